@@ -1,3 +1,8 @@
 module github.com/nashabanov/urlcheck
 
-go 1.23
+go 1.23.0
+
+require (
+	golang.org/x/net v0.43.0
+	gopkg.in/yaml.v3 v3.0.1
+)
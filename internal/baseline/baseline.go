@@ -0,0 +1,164 @@
+package baseline
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/output"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// DefaultLatencyThreshold - рост задержки в процентах, который считается регрессией
+const DefaultLatencyThreshold = 50
+
+// MinLatencyIncrease - рост задержки меньше этого значения не считается
+// регрессией, чтобы быстрые URL не давали ложных срабатываний
+const MinLatencyIncrease = 10 * time.Millisecond
+
+// Baseline - результаты предыдущего запуска по URL
+type Baseline struct {
+	Path string
+
+	// LatencyThreshold - допустимый рост задержки в процентах
+	LatencyThreshold float64
+
+	results map[string]output.JSONResult
+	// order - URL в порядке файла, чтобы вывод не зависел от map
+	order []string
+	seen  map[string]bool
+}
+
+// Load читает результаты, сохраненные с -output json или -output jsonl
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	results, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	b := &Baseline{
+		Path:             path,
+		LatencyThreshold: DefaultLatencyThreshold,
+		results:          map[string]output.JSONResult{},
+		seen:             map[string]bool{},
+	}
+	for _, r := range results {
+		// Для повторяющихся URL используется последний результат
+		if _, ok := b.results[r.URL]; !ok {
+			b.order = append(b.order, r.URL)
+		}
+		b.results[r.URL] = r
+	}
+
+	return b, nil
+}
+
+// parse разбирает документ json или строки jsonl
+func parse(data []byte) ([]output.JSONResult, error) {
+	var report output.JSONReport
+	if err := json.Unmarshal(data, &report); err == nil && report.Results != nil {
+		return report.Results, nil
+	}
+
+	var results []output.JSONResult
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		// Кроме результатов в jsonl есть итоги, у которых другие поля
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+			return nil, fmt.Errorf("not a urlcheck json or jsonl report (line %d: %v)", line, err)
+		}
+		if header.Type != "result" {
+			continue
+		}
+
+		var r output.JSONResult
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		results = append(results, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if results == nil {
+		return nil, fmt.Errorf("no results found")
+	}
+	return results, nil
+}
+
+// Compare возвращает отличия результата от базового запуска
+func (b *Baseline) Compare(result types.Result) []types.Change {
+	b.seen[result.URL] = true
+
+	before, ok := b.results[result.URL]
+	if !ok {
+		return []types.Change{{URL: result.URL, Kind: types.ChangeNew, Message: "not in baseline"}}
+	}
+
+	var changes []types.Change
+	change := func(kind, message string, regression bool) {
+		changes = append(changes, types.Change{URL: result.URL, Kind: kind, Message: message, Regression: regression})
+	}
+
+	// Смена кода ответа отдельно указывается, только если
+	// не изменился сам успех проверки: иначе код есть в описании
+	success := result.IsSuccess()
+	switch {
+	case before.Success && !success:
+		change(types.ChangeFailing, output.FailureReason(result), true)
+	case !before.Success && success:
+		change(types.ChangeRecovered, fmt.Sprintf("%d, %v", result.StatusCode, result.Duration.Round(time.Millisecond)), false)
+	case before.StatusCode != result.StatusCode:
+		change(types.ChangeStatus, fmt.Sprintf("%s -> %s", statusText(before.StatusCode), statusText(result.StatusCode)), false)
+	}
+
+	// Задержки сравниваются только у успешных проверок: у ошибок
+	// это время до сбоя, а не время ответа
+	if before.Success && success {
+		was := time.Duration(before.DurationMs * float64(time.Millisecond))
+		increase := result.Duration - was
+		if was > 0 && increase >= MinLatencyIncrease {
+			percent := float64(increase) / float64(was) * 100
+			if percent > b.LatencyThreshold {
+				change(types.ChangeSlower, fmt.Sprintf("%v -> %v (+%.0f%%)",
+					was.Round(time.Millisecond), result.Duration.Round(time.Millisecond), percent), true)
+			}
+		}
+	}
+
+	return changes
+}
+
+// Missing возвращает URL базового запуска, которые не сравнивались
+func (b *Baseline) Missing() []types.Change {
+	var changes []types.Change
+	for _, url := range b.order {
+		if !b.seen[url] {
+			changes = append(changes, types.Change{URL: url, Kind: types.ChangeMissing, Message: "not checked in this run"})
+		}
+	}
+	return changes
+}
+
+func statusText(code int) string {
+	if code == 0 {
+		return "no response"
+	}
+	return fmt.Sprint(code)
+}
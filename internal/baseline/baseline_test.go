@@ -0,0 +1,112 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+const reportJSON = `{
+  "results": [
+    {"url": "http://stable.com", "status": 200, "duration_ms": 100, "success": true},
+    {"url": "http://breaks.com", "status": 200, "duration_ms": 100, "success": true},
+    {"url": "http://heals.com", "status": 503, "duration_ms": 30, "success": false},
+    {"url": "http://moved.com", "status": 200, "duration_ms": 50, "success": true},
+    {"url": "http://slow.com", "status": 200, "duration_ms": 100, "success": true},
+    {"url": "http://fast.com", "status": 200, "duration_ms": 2, "success": true},
+    {"url": "http://gone.com", "status": 200, "duration_ms": 10, "success": true}
+  ],
+  "summary": {"total": 7, "success": 6, "failed": 1}
+}`
+
+const reportJSONL = `{"type":"result","url":"http://a.com","status":200,"duration_ms":10,"success":true}
+{"type":"result","url":"http://a.com","status":503,"duration_ms":10,"success":false}
+
+{"type":"summary","total":2,"success":1,"failed":1}
+`
+
+func writeReport(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBaseline_Compare(t *testing.T) {
+	b, err := Load(writeReport(t, reportJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		result     types.Result
+		kinds      []string
+		regression bool
+	}{
+		{types.Result{URL: "http://stable.com", StatusCode: 200, Duration: 120 * time.Millisecond}, nil, false},
+		{types.Result{URL: "http://breaks.com", Error: checker.ErrTimeout{URL: "http://breaks.com"}},
+			[]string{types.ChangeFailing}, true},
+		{types.Result{URL: "http://heals.com", StatusCode: 200, Duration: 30 * time.Millisecond},
+			[]string{types.ChangeRecovered}, false},
+		{types.Result{URL: "http://moved.com", StatusCode: 204, Duration: 50 * time.Millisecond},
+			[]string{types.ChangeStatus}, false},
+		{types.Result{URL: "http://slow.com", StatusCode: 200, Duration: 151 * time.Millisecond},
+			[]string{types.ChangeSlower}, true},
+		// Рост в 4 раза, но меньше MinLatencyIncrease
+		{types.Result{URL: "http://fast.com", StatusCode: 200, Duration: 8 * time.Millisecond}, nil, false},
+		{types.Result{URL: "http://new.com", StatusCode: 200}, []string{types.ChangeNew}, false},
+	}
+
+	for _, tc := range testCases {
+		changes := b.Compare(tc.result)
+
+		var kinds []string
+		regression := false
+		for _, c := range changes {
+			kinds = append(kinds, c.Kind)
+			regression = regression || c.Regression
+		}
+
+		if len(kinds) != len(tc.kinds) || (len(kinds) > 0 && kinds[0] != tc.kinds[0]) {
+			t.Errorf("%s: expected changes %v, got %+v", tc.result.URL, tc.kinds, changes)
+		}
+		if regression != tc.regression {
+			t.Errorf("%s: expected regression %v, got %v", tc.result.URL, tc.regression, regression)
+		}
+	}
+
+	missing := b.Missing()
+	if len(missing) != 1 || missing[0].URL != "http://gone.com" || missing[0].Kind != types.ChangeMissing {
+		t.Errorf("Expected http://gone.com to be missing, got %+v", missing)
+	}
+}
+
+func TestLoad_JSONLines(t *testing.T) {
+	b, err := Load(writeReport(t, reportJSONL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Используется последний результат для URL
+	changes := b.Compare(types.Result{URL: "http://a.com", StatusCode: 200, Duration: 10 * time.Millisecond})
+	if len(changes) != 1 || changes[0].Kind != types.ChangeRecovered {
+		t.Errorf("Expected recovered, got %+v", changes)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for _, content := range []string{"", "url,status\n", `{"type":"summary","total":0}`} {
+		if _, err := Load(writeReport(t, content)); err == nil {
+			t.Errorf("Expected error for %q", content)
+		}
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
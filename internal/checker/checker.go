@@ -1,7 +1,13 @@
 package checker
 
-import "github.com/nashabanov/urlcheck/internal/types"
+import (
+	"context"
 
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// Checker проверяет одну цель. Реализации обязаны прерывать проверку,
+// как только ctx отменён.
 type Checker interface {
-	Check(url string) *types.Result
+	Check(ctx context.Context, target types.Target) *types.Result
 }
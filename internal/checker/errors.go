@@ -1,35 +1,306 @@
 package checker
 
-import "fmt"
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+)
+
+// Классы ошибок, используемые в структурированном выводе
+const (
+	ClassTimeout            = "timeout"
+	ClassDNS                = "dns"
+	ClassConnectionRefused  = "connection_refused"
+	ClassConnectionReset    = "connection_reset"
+	ClassConnectionClosed   = "connection_closed"
+	ClassHostUnreachable    = "host_unreachable"
+	ClassTLSHandshake       = "tls_handshake"
+	ClassCertificateInvalid = "certificate_invalid"
+	ClassCertificateExpired = "certificate_expired"
+	ClassTooManyRedirects   = "too_many_redirects"
+	ClassRedirectLoop       = "redirect_loop"
+	ClassNetwork            = "network"
+	ClassCanceled           = "canceled"
+	ClassOther              = "other"
+
+	// ClassAssertion - класс отказа для проверок без ошибки запроса,
+	// не выполнивших ожидания
+	ClassAssertion = "assertion"
+)
 
 type ErrTimeout struct {
 	URL string
+	Err error
 }
 
 func (e ErrTimeout) Error() string {
-	return fmt.Sprintf("timeout for %s", e.URL)
+	return withCause(fmt.Sprintf("timeout for %s", e.URL), e.Err)
 }
 
+func (e ErrTimeout) Unwrap() error { return e.Err }
+
+func (e ErrTimeout) Class() string { return ClassTimeout }
+
 type ErrDNSFailed struct {
 	URL string
+	Err error
 }
 
 func (e ErrDNSFailed) Error() string {
-	return fmt.Sprintf("DNS lookup failed for %s", e.URL)
+	return withCause(fmt.Sprintf("DNS lookup failed for %s", e.URL), e.Err)
 }
 
+func (e ErrDNSFailed) Unwrap() error { return e.Err }
+
+func (e ErrDNSFailed) Class() string { return ClassDNS }
+
 type ErrConnectionRefused struct {
 	URL string
+	Err error
 }
 
 func (e ErrConnectionRefused) Error() string {
-	return fmt.Sprintf("connection refused for %s", e.URL)
+	return withCause(fmt.Sprintf("connection refused for %s", e.URL), e.Err)
+}
+
+func (e ErrConnectionRefused) Unwrap() error { return e.Err }
+
+func (e ErrConnectionRefused) Class() string { return ClassConnectionRefused }
+
+type ErrConnectionReset struct {
+	URL string
+	Err error
+}
+
+func (e ErrConnectionReset) Error() string {
+	return withCause(fmt.Sprintf("connection reset for %s", e.URL), e.Err)
+}
+
+func (e ErrConnectionReset) Unwrap() error { return e.Err }
+
+func (e ErrConnectionReset) Class() string { return ClassConnectionReset }
+
+// ErrConnectionClosed - сервер закрыл соединение без ответа или оборвал
+// ответ (EOF), в отличие от сброса соединения (RST)
+type ErrConnectionClosed struct {
+	URL string
+	Err error
+}
+
+func (e ErrConnectionClosed) Error() string {
+	return withCause(fmt.Sprintf("connection closed by server for %s", e.URL), e.Err)
+}
+
+func (e ErrConnectionClosed) Unwrap() error { return e.Err }
+
+func (e ErrConnectionClosed) Class() string { return ClassConnectionClosed }
+
+type ErrHostUnreachable struct {
+	URL string
+	Err error
+}
+
+func (e ErrHostUnreachable) Error() string {
+	return withCause(fmt.Sprintf("host unreachable for %s", e.URL), e.Err)
+}
+
+func (e ErrHostUnreachable) Unwrap() error { return e.Err }
+
+func (e ErrHostUnreachable) Class() string { return ClassHostUnreachable }
+
+type ErrTLSHandshake struct {
+	URL string
+	Err error
+}
+
+func (e ErrTLSHandshake) Error() string {
+	return withCause(fmt.Sprintf("TLS handshake failed for %s", e.URL), e.Err)
+}
+
+func (e ErrTLSHandshake) Unwrap() error { return e.Err }
+
+func (e ErrTLSHandshake) Class() string { return ClassTLSHandshake }
+
+type ErrCertificateInvalid struct {
+	URL string
+	Err error
+}
+
+func (e ErrCertificateInvalid) Error() string {
+	return withCause(fmt.Sprintf("invalid certificate for %s", e.URL), e.Err)
+}
+
+func (e ErrCertificateInvalid) Unwrap() error { return e.Err }
+
+func (e ErrCertificateInvalid) Class() string { return ClassCertificateInvalid }
+
+type ErrCertificateExpired struct {
+	URL string
+	Err error
+}
+
+func (e ErrCertificateExpired) Error() string {
+	return withCause(fmt.Sprintf("certificate expired for %s", e.URL), e.Err)
 }
 
+func (e ErrCertificateExpired) Unwrap() error { return e.Err }
+
+func (e ErrCertificateExpired) Class() string { return ClassCertificateExpired }
+
+type ErrTooManyRedirects struct {
+	URL string
+	Err error
+}
+
+func (e ErrTooManyRedirects) Error() string {
+	return withCause(fmt.Sprintf("too many redirects for %s", e.URL), e.Err)
+}
+
+func (e ErrTooManyRedirects) Unwrap() error { return e.Err }
+
+func (e ErrTooManyRedirects) Class() string { return ClassTooManyRedirects }
+
+type ErrRedirectLoop struct {
+	URL string
+	Err error
+}
+
+func (e ErrRedirectLoop) Error() string {
+	return withCause(fmt.Sprintf("redirect loop for %s", e.URL), e.Err)
+}
+
+func (e ErrRedirectLoop) Unwrap() error { return e.Err }
+
+func (e ErrRedirectLoop) Class() string { return ClassRedirectLoop }
+
 type ErrNetwork struct {
 	URL string
+	Err error
 }
 
 func (e ErrNetwork) Error() string {
-	return fmt.Sprintf("network error for %s", e.URL)
+	return withCause(fmt.Sprintf("network error for %s", e.URL), e.Err)
+}
+
+func (e ErrNetwork) Unwrap() error { return e.Err }
+
+func (e ErrNetwork) Class() string { return ClassNetwork }
+
+// ErrOther - ошибка запроса, не связанная с сетью, например неверная
+// схема URL. Такие ошибки не временные и не повторяются.
+type ErrOther struct {
+	URL string
+	Err error
+}
+
+func (e ErrOther) Error() string {
+	return withCause(fmt.Sprintf("request failed for %s", e.URL), e.Err)
+}
+
+func (e ErrOther) Unwrap() error { return e.Err }
+
+func (e ErrOther) Class() string { return ClassOther }
+
+func withCause(msg string, cause error) string {
+	if cause == nil {
+		return msg
+	}
+	return msg + ": " + cause.Error()
+}
+
+// classifyError превращает ошибку http.Client в типизированную ошибку,
+// сохраняя исходную причину. Порядок проверок важен: DNS-ошибки и ошибки
+// TLS тоже реализуют net.Error, поэтому таймаут проверяется после них.
+func classifyError(target string, err error) error {
+	// *url.Error лишь повторяет метод и URL, причина - внутри него
+	cause := err
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		cause = urlErr.Err
+	}
+
+	var (
+		dnsErr        *net.DNSError
+		certInvalid   x509.CertificateInvalidError
+		unknownAuth   x509.UnknownAuthorityError
+		hostnameErr   x509.HostnameError
+		recordErr     tls.RecordHeaderError
+		alertErr      tls.AlertError
+		netErr        net.Error
+		opErr         *net.OpError
+		verifyErr     *tls.CertificateVerificationError
+		systemRootErr x509.SystemRootsError
+	)
+
+	switch {
+	case errors.Is(cause, errTooManyRedirects):
+		return ErrTooManyRedirects{URL: target, Err: cause}
+
+	case errors.Is(cause, errRedirectLoop):
+		return ErrRedirectLoop{URL: target, Err: cause}
+
+	case errors.As(cause, &dnsErr):
+		return ErrDNSFailed{URL: target, Err: cause}
+
+	case errors.As(cause, &certInvalid) && certInvalid.Reason == x509.Expired:
+		return ErrCertificateExpired{URL: target, Err: cause}
+
+	case errors.As(cause, &certInvalid), errors.As(cause, &unknownAuth),
+		errors.As(cause, &hostnameErr), errors.As(cause, &systemRootErr),
+		errors.As(cause, &verifyErr):
+		return ErrCertificateInvalid{URL: target, Err: cause}
+
+	case errors.As(cause, &recordErr), errors.As(cause, &alertErr),
+		// http.Client заменяет tls.RecordHeaderError этой ошибкой,
+		// если сервер ответил по HTTP
+		errors.Is(cause, http.ErrSchemeMismatch):
+		return ErrTLSHandshake{URL: target, Err: cause}
+
+	case errors.Is(cause, syscall.ECONNREFUSED):
+		return ErrConnectionRefused{URL: target, Err: cause}
+
+	case errors.Is(cause, syscall.ECONNRESET), errors.Is(cause, syscall.EPIPE):
+		return ErrConnectionReset{URL: target, Err: cause}
+
+	case errors.Is(cause, io.EOF), errors.Is(cause, io.ErrUnexpectedEOF):
+		return ErrConnectionClosed{URL: target, Err: cause}
+
+	case errors.Is(cause, syscall.EHOSTUNREACH), errors.Is(cause, syscall.ENETUNREACH):
+		return ErrHostUnreachable{URL: target, Err: cause}
+
+	case errors.As(cause, &netErr) && netErr.Timeout(),
+		errors.Is(cause, context.DeadlineExceeded):
+		return ErrTimeout{URL: target, Err: cause}
+
+	case errors.As(cause, &netErr), errors.As(cause, &opErr):
+		return ErrNetwork{URL: target, Err: cause}
+	}
+
+	return ErrOther{URL: target, Err: cause}
+}
+
+// ErrorClass возвращает короткое машиночитаемое имя класса ошибки.
+// Для nil возвращается пустая строка.
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var classified interface{ Class() string }
+	if errors.As(err, &classified) {
+		return classified.Class()
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ClassCanceled
+	}
+
+	return ClassOther
 }
@@ -0,0 +1,289 @@
+package checker
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func TestClassifyError_Synthetic(t *testing.T) {
+	opErr := func(errno syscall.Errno) error {
+		return &url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{
+			Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: errno},
+		}}
+	}
+
+	testCases := []struct {
+		name  string
+		err   error
+		class string
+	}{
+		{"refused", opErr(syscall.ECONNREFUSED), ClassConnectionRefused},
+		{"reset", opErr(syscall.ECONNRESET), ClassConnectionReset},
+		{"closed", &url.Error{Op: "Get", URL: "http://x", Err: io.EOF}, ClassConnectionClosed},
+		{"truncated", fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF), ClassConnectionClosed},
+		{"scheme mismatch", &url.Error{Op: "Get", URL: "https://x", Err: http.ErrSchemeMismatch}, ClassTLSHandshake},
+		{"host unreachable", opErr(syscall.EHOSTUNREACH), ClassHostUnreachable},
+		{"network unreachable", opErr(syscall.ENETUNREACH), ClassHostUnreachable},
+		{"dns", &url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{
+			Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "x", IsNotFound: true},
+		}}, ClassDNS},
+		{"dns timeout", &url.Error{Op: "Get", URL: "http://x", Err: &net.DNSError{
+			Err: "i/o timeout", Name: "x", IsTimeout: true,
+		}}, ClassDNS},
+		{"expired", &tls.CertificateVerificationError{
+			Err: x509.CertificateInvalidError{Reason: x509.Expired},
+		}, ClassCertificateExpired},
+		{"hostname", x509.HostnameError{Host: "x"}, ClassCertificateInvalid},
+		{"alert", &net.OpError{Op: "remote error", Err: tls.AlertError(40)}, ClassTLSHandshake},
+		{"redirects", &url.Error{Op: "Get", URL: "http://x", Err: errTooManyRedirects}, ClassTooManyRedirects},
+		{"redirect loop", &url.Error{Op: "Get", URL: "http://x", Err: errRedirectLoop}, ClassRedirectLoop},
+		{"deadline", &url.Error{Op: "Get", URL: "http://x", Err: context.DeadlineExceeded}, ClassTimeout},
+		{"network", &url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{
+			Op: "read", Net: "tcp", Err: errors.New("something odd"),
+		}}, ClassNetwork},
+		{"scheme", &url.Error{Op: "Get", URL: "ftp://x", Err: errors.New(`unsupported protocol scheme "ftp"`)}, ClassOther},
+		{"unknown", errors.New("something odd"), ClassOther},
+	}
+
+	for _, tc := range testCases {
+		classified := classifyError("http://x", tc.err)
+
+		if got := ErrorClass(classified); got != tc.class {
+			t.Errorf("%s: expected class %s, got %s (%v)", tc.name, tc.class, got, classified)
+		}
+		if errors.Unwrap(classified) == nil {
+			t.Errorf("%s: expected classified error to wrap its cause", tc.name)
+		}
+	}
+}
+
+func TestHTTPChecker_ErrorClasses(t *testing.T) {
+	testCases := []struct {
+		name    string
+		url     func(t *testing.T) string
+		timeout time.Duration
+		class   string
+		cause   error
+	}{
+		{name: "connection refused", url: closedPortURL, class: ClassConnectionRefused, cause: syscall.ECONNREFUSED},
+		{name: "connection reset", url: resettingServerURL, class: ClassConnectionReset},
+		{name: "connection closed", url: closingServerURL, class: ClassConnectionClosed, cause: io.EOF},
+		{name: "timeout", url: silentServerURL, timeout: 100 * time.Millisecond, class: ClassTimeout},
+		{name: "tls handshake", url: plainServerAsHTTPSURL, class: ClassTLSHandshake},
+		{name: "certificate invalid", url: untrustedTLSServerURL, class: ClassCertificateInvalid},
+		{name: "certificate expired", url: expiredTLSServerURL, class: ClassCertificateExpired},
+		{name: "too many redirects", url: endlessRedirectURL, class: ClassTooManyRedirects, cause: errTooManyRedirects},
+		{name: "redirect loop", url: redirectLoopURL, class: ClassRedirectLoop, cause: errRedirectLoop},
+		{name: "dns", url: func(*testing.T) string { return "http://urlcheck-test.invalid/" }, class: ClassDNS},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hc := NewHTTPChecker()
+			if tc.timeout > 0 {
+				hc.Timeout = tc.timeout
+			}
+
+			result := hc.Check(context.Background(), types.Target{URL: tc.url(t)})
+
+			if got := ErrorClass(result.Error); got != tc.class {
+				t.Errorf("Expected class %s, got %s (%v)", tc.class, got, result.Error)
+			}
+			if tc.cause != nil && !errors.Is(result.Error, tc.cause) {
+				t.Errorf("Expected error to wrap %v, got %v", tc.cause, result.Error)
+			}
+		})
+	}
+}
+
+func closedPortURL(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return "http://" + addr + "/"
+}
+
+// resettingServerURL принимает соединение и сразу сбрасывает его (RST)
+func resettingServerURL(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 1024)
+			conn.Read(buf)
+			conn.(*net.TCPConn).SetLinger(0)
+			conn.Close()
+		}
+	}()
+
+	return "http://" + ln.Addr().String() + "/"
+}
+
+// closingServerURL читает запрос и закрывает соединение, ничего не ответив
+func closingServerURL(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 1024)
+			conn.Read(buf)
+			conn.Close()
+		}
+	}()
+
+	return "http://" + ln.Addr().String() + "/"
+}
+
+// silentServerURL принимает соединения, но никогда не отвечает
+func silentServerURL(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var conns []net.Conn
+	t.Cleanup(func() {
+		ln.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, c := range conns {
+			c.Close()
+		}
+	})
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+
+	return "http://" + ln.Addr().String() + "/"
+}
+
+func plainServerAsHTTPSURL(t *testing.T) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+	return "https://" + server.Listener.Addr().String() + "/"
+}
+
+func untrustedTLSServerURL(t *testing.T) string {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func expiredTLSServerURL(t *testing.T) string {
+	cert := selfSignedCert(t, time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+// endlessRedirectURL каждый раз перенаправляет на новый адрес
+func endlessRedirectURL(t *testing.T) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/"
+}
+
+func redirectLoopURL(t *testing.T) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path, http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/loop"
+}
+
+func selfSignedCert(t *testing.T, notBefore, notAfter time.Time) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestErrTypes_Messages(t *testing.T) {
+	cause := fmt.Errorf("dial tcp: connection refused")
+
+	if got := (ErrConnectionRefused{URL: "http://x"}).Error(); got != "connection refused for http://x" {
+		t.Errorf("Unexpected message without cause: %s", got)
+	}
+	if got := (ErrConnectionRefused{URL: "http://x", Err: cause}).Error(); got != "connection refused for http://x: dial tcp: connection refused" {
+		t.Errorf("Unexpected message with cause: %s", got)
+	}
+}
@@ -1,59 +1,205 @@
 package checker
 
 import (
-	"net"
+	"bytes"
+	"context"
+	"io"
 	"net/http"
+	"net/http/httptrace"
+	"slices"
 	"time"
 
 	"github.com/nashabanov/urlcheck/internal/types"
 )
 
+// MaxBodySize ограничивает объем тела ответа, читаемого для проверки ожиданий
+const MaxBodySize = 1 << 20
+
 type HTTPChecker struct {
-	Timeout time.Duration
+	Timeout  time.Duration
+	Retry    RetryPolicy
+	Redirect RedirectPolicy
+
+	// CertWarn - за сколько до истечения сертификата цепочки результат
+	// получает предупреждение; 0 отключает проверку
+	CertWarn time.Duration
+
+	// Transport используется для запросов; nil означает http.DefaultTransport
+	Transport http.RoundTripper
 }
 
 func NewHTTPChecker() *HTTPChecker {
 	return &HTTPChecker{
-		Timeout: 5 * time.Second,
+		Timeout:  5 * time.Second,
+		Retry:    DefaultRetryPolicy(),
+		Redirect: DefaultRedirectPolicy(),
 	}
 }
 
-func (hc *HTTPChecker) Check(url string) *types.Result {
+// Check выполняет запрос, повторяя его при временных сбоях согласно Retry.
+// Duration результата - длительность последней попытки, все попытки
+// записываются в Attempts.
+func (hc *HTTPChecker) Check(ctx context.Context, target types.Target) *types.Result {
+	var attempts []types.Attempt
 	start := time.Now()
-	client := &http.Client{Timeout: hc.Timeout}
-	resp, err := client.Get(url)
+
+	for attempt := 0; ; attempt++ {
+		result, retryAfter := hc.checkOnce(ctx, target)
+		result.StartedAt = start
+		attempts = append(attempts, types.Attempt{
+			StatusCode: result.StatusCode,
+			Duration:   result.Duration,
+			Error:      result.Error,
+		})
+		result.Attempts = attempts
+
+		if attempt >= hc.Retry.MaxRetries || !hc.Retry.shouldRetry(result) {
+			return result
+		}
+
+		if !sleepContext(ctx, hc.Retry.delay(attempt, retryAfter)) {
+			return result
+		}
+	}
+}
+
+// checkOnce выполняет одну попытку и возвращает задержку из Retry-After,
+// если сервер попросил повторить позже. Если сервер не поддерживает HEAD,
+// а цель это допускает, запрос повторяется методом GET.
+func (hc *HTTPChecker) checkOnce(ctx context.Context, target types.Target) (*types.Result, time.Duration) {
+	method := target.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	result, retryAfter := hc.do(ctx, target, method)
+
+	if method == http.MethodHead && target.HeadFallback && result.Error == nil &&
+		(result.StatusCode == http.StatusMethodNotAllowed || result.StatusCode == http.StatusNotImplemented) {
+		return hc.do(ctx, target, http.MethodGet)
+	}
+
+	return result, retryAfter
+}
+
+// do выполняет один HTTP-запрос к цели указанным методом
+func (hc *HTTPChecker) do(ctx context.Context, target types.Target, method string) (*types.Result, time.Duration) {
+	url := target.URL
+	start := time.Now()
+
+	var reqBody io.Reader
+	if target.Body != nil {
+		reqBody = bytes.NewReader(target.Body)
+	}
+
+	tracer := &phaseTracer{}
+	ctx = httptrace.WithClientTrace(ctx, tracer.clientTrace())
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return &types.Result{
+			URL:      url,
+			Method:   method,
+			Duration: time.Since(start),
+			Error:    err,
+		}, 0
+	}
+
+	for name, values := range target.Headers {
+		req.Header[name] = values
+	}
+	// Host нельзя задать через заголовки, только через поле запроса
+	if host := target.Headers.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	redirects := newRedirectTracker(hc.Redirect, start)
+	client := &http.Client{
+		Transport:     hc.Transport,
+		Timeout:       hc.Timeout,
+		CheckRedirect: redirects.checkRedirect,
+	}
+	resp, err := client.Do(req)
 	duration := time.Since(start)
 
 	if err != nil {
-		var typedErr error
-
-		if netErr, ok := err.(net.Error); ok {
-			if netErr.Timeout() {
-				typedErr = ErrTimeout{URL: url}
-			} else if netErr.Timeout() {
-				typedErr = ErrConnectionRefused{URL: url}
-			} else {
-				typedErr = ErrNetwork{URL: url}
-			}
-		} else if _, ok := err.(*net.DNSError); ok {
-			typedErr = ErrDNSFailed{URL: url}
-		} else {
-			typedErr = ErrNetwork{URL: url}
+		typedErr := classifyError(url, err)
+		if ctx.Err() != nil {
+			typedErr = ctx.Err()
 		}
 
 		return &types.Result{
 			URL:        url,
+			Method:     method,
 			StatusCode: 0,
 			Duration:   duration,
 			Error:      typedErr,
-		}
+			Redirects:  redirects.hops,
+			Timing:     tracer.result(),
+		}, 0
 	}
 
 	defer resp.Body.Close()
+
+	// Тело читается всегда, чтобы измерить время передачи,
+	// но сохраняется, только если его проверяют ожидания или просит цель
+	var body []byte
+	var read int64
+	if target.Expect.NeedsBody() || target.KeepBody {
+		body, err = io.ReadAll(io.LimitReader(resp.Body, MaxBodySize))
+		read = int64(len(body))
+	} else {
+		read, err = io.Copy(io.Discard, io.LimitReader(resp.Body, MaxBodySize))
+	}
+	timing := tracer.finish(time.Now())
+
+	// Тело длиннее MaxBodySize дочитывается не до конца, и его размер неизвестен
+	contentLength := resp.ContentLength
+	if contentLength < 0 && read < MaxBodySize {
+		contentLength = read
+	}
+
+	if err != nil {
+		typedErr := classifyError(url, err)
+		if ctx.Err() != nil {
+			typedErr = ctx.Err()
+		}
+		return &types.Result{
+			URL:        url,
+			Method:     method,
+			StatusCode: resp.StatusCode,
+			Duration:   time.Since(start),
+			Error:      typedErr,
+			Redirects:  redirects.hops,
+			FinalURL:   resp.Request.URL.String(),
+			Timing:     timing,
+		}, 0
+	}
+
+	var retryAfter time.Duration
+	if slices.Contains(hc.Retry.Statuses, resp.StatusCode) {
+		retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+
+	var tlsInfo *types.TLSInfo
+	if resp.TLS != nil {
+		tlsInfo = newTLSInfo(resp.TLS)
+	}
+
 	return &types.Result{
 		URL:        url,
+		Method:     method,
 		StatusCode: resp.StatusCode,
 		Duration:   duration,
 		Error:      nil,
-	}
+		Redirects:  redirects.hops,
+		FinalURL:   resp.Request.URL.String(),
+		TLS:        tlsInfo,
+		Timing:     timing,
+		Warnings:   certWarnings(tlsInfo, hc.CertWarn, time.Now()),
+
+		ContentLength: contentLength,
+		Header:        resp.Header,
+		Body:          body,
+	}, retryAfter
 }
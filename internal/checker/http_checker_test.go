@@ -0,0 +1,140 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func TestHTTPChecker_OK(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	result := NewHTTPChecker().Check(context.Background(), types.Target{URL: server.URL})
+
+	if result.Error != nil {
+		t.Fatalf("Expected no error, got %v", result.Error)
+	}
+	if result.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", result.StatusCode)
+	}
+}
+
+func TestHTTPChecker_ContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	hc := NewHTTPChecker()
+	hc.Timeout = 10 * time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	result := hc.Check(ctx, types.Target{URL: server.URL})
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected Check to stop after cancellation, took %v", elapsed)
+	}
+	if !errors.Is(result.Error, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", result.Error)
+	}
+}
+
+func TestHTTPChecker_RequestParameters(t *testing.T) {
+	var method, apiKey, host, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, apiKey, host, body = r.Method, r.Header.Get("X-Api-Key"), r.Host, string(data)
+	}))
+	defer server.Close()
+
+	target := types.Target{URL: server.URL}
+	err := RequestSpec{
+		Body:    `{"ping": true}`,
+		Headers: map[string]string{"x-api-key": "secret", "Host": "health.local"},
+	}.Apply(&target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewHTTPChecker().Check(context.Background(), target)
+
+	if result.Error != nil {
+		t.Fatalf("Expected no error, got %v", result.Error)
+	}
+	if method != http.MethodPost || result.Method != http.MethodPost {
+		t.Errorf("Expected POST, got %s (result %s)", method, result.Method)
+	}
+	if apiKey != "secret" {
+		t.Errorf("Expected X-Api-Key header, got %q", apiKey)
+	}
+	if host != "health.local" {
+		t.Errorf("Expected Host health.local, got %q", host)
+	}
+	if body != `{"ping": true}` {
+		t.Errorf("Expected request body, got %q", body)
+	}
+}
+
+func TestHTTPChecker_HeadFallback(t *testing.T) {
+	testCases := []struct {
+		name           string
+		fallback       bool
+		expectedStatus int
+		expectedMethod string
+	}{
+		{"without fallback", false, http.StatusMethodNotAllowed, http.MethodHead},
+		{"with fallback", true, http.StatusOK, http.MethodGet},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	for _, tc := range testCases {
+		target := types.Target{URL: server.URL, Method: http.MethodHead, HeadFallback: tc.fallback}
+		result := NewHTTPChecker().Check(context.Background(), target)
+
+		if result.StatusCode != tc.expectedStatus || result.Method != tc.expectedMethod {
+			t.Errorf("%s: expected %s %d, got %s %d",
+				tc.name, tc.expectedMethod, tc.expectedStatus, result.Method, result.StatusCode)
+		}
+	}
+}
+
+func TestHTTPChecker_ContentLength(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// Flush до записи тела отключает Content-Length
+			w.(http.Flusher).Flush()
+		}
+		io.WriteString(w, "hello")
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/", "/chunked"} {
+		result := NewHTTPChecker().Check(context.Background(), types.Target{URL: server.URL + path})
+		if result.ContentLength != 5 {
+			t.Errorf("%s: expected content length 5, got %d", path, result.ContentLength)
+		}
+	}
+}
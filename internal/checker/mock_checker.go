@@ -1,6 +1,7 @@
 package checker
 
 import (
+	"context"
 	"time"
 
 	"github.com/nashabanov/urlcheck/internal/types"
@@ -10,12 +11,22 @@ type MockChecker struct {
 	Delay time.Duration
 }
 
-func (mc *MockChecker) Check(url string) *types.Result {
+func (mc *MockChecker) Check(ctx context.Context, target types.Target) *types.Result {
 	if mc.Delay > 0 {
-		time.Sleep(mc.Delay)
+		timer := time.NewTimer(mc.Delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return &types.Result{
+				URL:   target.URL,
+				Error: ctx.Err(),
+			}
+		}
 	}
 	return &types.Result{
-		URL:        url,
+		URL:        target.URL,
 		StatusCode: 200,
 		Duration:   100 * time.Millisecond,
 		Error:      nil,
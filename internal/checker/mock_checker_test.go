@@ -1,6 +1,7 @@
 package checker
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -30,13 +31,30 @@ func checkResult(t *testing.T, result *types.Result, expectedURL string) {
 func TestMockChecker(t *testing.T) {
 	url := "https://example.com"
 	mc := MockChecker{}
-	result := mc.Check(url)
+	result := mc.Check(context.Background(), types.Target{URL: url})
 	checkResult(t, result, url)
 }
 
 func TestMockChecker_EmptyUrl(t *testing.T) {
 	url := ""
 	mc := MockChecker{}
-	result := mc.Check(url)
+	result := mc.Check(context.Background(), types.Target{URL: url})
 	checkResult(t, result, url)
 }
+
+func TestMockChecker_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mc := MockChecker{Delay: 5 * time.Second}
+
+	start := time.Now()
+	result := mc.Check(ctx, types.Target{URL: "https://example.com"})
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Check to return immediately, took %v", elapsed)
+	}
+	if result.Error != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", result.Error)
+	}
+}
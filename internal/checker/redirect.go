@@ -0,0 +1,101 @@
+package checker
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// Режимы следования редиректам
+const (
+	RedirectFollow   = "follow"
+	RedirectNone     = "none"
+	RedirectSameHost = "same-host"
+)
+
+// DefaultMaxRedirects совпадает с лимитом http.Client по умолчанию
+const DefaultMaxRedirects = 10
+
+var (
+	// errTooManyRedirects возвращается из CheckRedirect при превышении лимита
+	errTooManyRedirects = errors.New("too many redirects")
+	// errRedirectLoop возвращается, если редирект ведет на уже посещенный URL
+	errRedirectLoop = errors.New("redirect loop")
+)
+
+// RedirectPolicy определяет, каким редиректам следует HTTPChecker.
+// Если редирект не разрешен политикой, результатом проверки становится
+// сам ответ 3xx.
+type RedirectPolicy struct {
+	// Mode - RedirectFollow, RedirectNone или RedirectSameHost;
+	// пустая строка означает RedirectFollow
+	Mode string
+	// Max - наибольшее число редиректов, после которого запрос завершается
+	// ошибкой ErrTooManyRedirects; 0 означает DefaultMaxRedirects
+	Max int
+}
+
+func DefaultRedirectPolicy() RedirectPolicy {
+	return RedirectPolicy{Mode: RedirectFollow, Max: DefaultMaxRedirects}
+}
+
+// ValidRedirectMode сообщает, поддерживается ли режим следования редиректам
+func ValidRedirectMode(mode string) bool {
+	switch mode {
+	case RedirectFollow, RedirectNone, RedirectSameHost:
+		return true
+	}
+	return false
+}
+
+// redirectTracker применяет политику к редиректам одного запроса
+// и записывает пройденные шаги цепочки
+type redirectTracker struct {
+	policy   RedirectPolicy
+	hops     []types.Redirect
+	hopStart time.Time
+}
+
+func newRedirectTracker(policy RedirectPolicy, start time.Time) *redirectTracker {
+	return &redirectTracker{policy: policy, hopStart: start}
+}
+
+// checkRedirect используется как http.Client.CheckRedirect
+func (t *redirectTracker) checkRedirect(req *http.Request, via []*http.Request) error {
+	switch t.policy.Mode {
+	case RedirectNone:
+		return http.ErrUseLastResponse
+	case RedirectSameHost:
+		// Схема и порт могут меняться, например при переходе с http на https
+		if !strings.EqualFold(req.URL.Hostname(), via[0].URL.Hostname()) {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	now := time.Now()
+	t.hops = append(t.hops, types.Redirect{
+		URL:        via[len(via)-1].URL.String(),
+		StatusCode: req.Response.StatusCode,
+		Duration:   now.Sub(t.hopStart),
+	})
+	t.hopStart = now
+
+	for _, prev := range via {
+		if prev.URL.String() == req.URL.String() {
+			return errRedirectLoop
+		}
+	}
+
+	limit := t.policy.Max
+	if limit <= 0 {
+		limit = DefaultMaxRedirects
+	}
+	if len(via) > limit {
+		return errTooManyRedirects
+	}
+
+	return nil
+}
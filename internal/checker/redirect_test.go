@@ -0,0 +1,93 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// redirectServer отвечает 301 на /a -> /b -> /c, /c отдает 200,
+// а /away уводит на другой хост
+func redirectServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusFound)
+		case "/away":
+			// localhost и 127.0.0.1 - разные хосты для политики same-host
+			http.Redirect(w, r, strings.Replace("http://"+r.Host+"/c", "127.0.0.1", "localhost", 1), http.StatusFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPChecker_RedirectChain(t *testing.T) {
+	server := redirectServer(t)
+
+	result := NewHTTPChecker().Check(context.Background(), types.Target{URL: server.URL + "/a"})
+
+	if result.Error != nil || result.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 without error, got %d %v", result.StatusCode, result.Error)
+	}
+	if len(result.Redirects) != 2 {
+		t.Fatalf("Expected 2 redirects, got %+v", result.Redirects)
+	}
+
+	expected := []types.Redirect{
+		{URL: server.URL + "/a", StatusCode: http.StatusMovedPermanently},
+		{URL: server.URL + "/b", StatusCode: http.StatusFound},
+	}
+	for i, hop := range result.Redirects {
+		if hop.URL != expected[i].URL || hop.StatusCode != expected[i].StatusCode {
+			t.Errorf("Hop %d: expected %s %d, got %s %d",
+				i, expected[i].URL, expected[i].StatusCode, hop.URL, hop.StatusCode)
+		}
+		if hop.Duration <= 0 {
+			t.Errorf("Hop %d: expected positive duration", i)
+		}
+	}
+	if result.FinalURL != server.URL+"/c" {
+		t.Errorf("Expected final URL %s/c, got %s", server.URL, result.FinalURL)
+	}
+}
+
+func TestHTTPChecker_RedirectPolicy(t *testing.T) {
+	server := redirectServer(t)
+
+	testCases := []struct {
+		name           string
+		policy         RedirectPolicy
+		path           string
+		expectedStatus int
+		expectedHops   int
+		expectedClass  string
+	}{
+		{"none", RedirectPolicy{Mode: RedirectNone}, "/a", http.StatusMovedPermanently, 0, ""},
+		{"follow within limit", RedirectPolicy{Mode: RedirectFollow, Max: 2}, "/a", http.StatusOK, 2, ""},
+		{"follow over limit", RedirectPolicy{Mode: RedirectFollow, Max: 1}, "/a", 0, 2, ClassTooManyRedirects},
+		{"same host", RedirectPolicy{Mode: RedirectSameHost}, "/a", http.StatusOK, 2, ""},
+		{"other host", RedirectPolicy{Mode: RedirectSameHost}, "/away", http.StatusFound, 0, ""},
+	}
+
+	for _, tc := range testCases {
+		hc := NewHTTPChecker()
+		hc.Redirect = tc.policy
+
+		result := hc.Check(context.Background(), types.Target{URL: server.URL + tc.path})
+
+		if result.StatusCode != tc.expectedStatus || len(result.Redirects) != tc.expectedHops {
+			t.Errorf("%s: expected status %d with %d hops, got %d with %d",
+				tc.name, tc.expectedStatus, tc.expectedHops, result.StatusCode, len(result.Redirects))
+		}
+		if got := ErrorClass(result.Error); got != tc.expectedClass {
+			t.Errorf("%s: expected error class %q, got %q (%v)", tc.name, tc.expectedClass, got, result.Error)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package checker
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// RequestSpec - параметры запроса в том виде, в каком они задаются во флагах
+// и файле конфигурации. Пустое поле означает "не задано".
+type RequestSpec struct {
+	Method       string            `yaml:"method"`
+	Headers      map[string]string `yaml:"headers"`
+	Body         string            `yaml:"body"`
+	BodyFile     string            `yaml:"body_file"`
+	HeadFallback *bool             `yaml:"head_fallback"`
+}
+
+// Merge возвращает копию s, в которой заданные в over поля заменяют исходные.
+// Заголовки объединяются, при совпадении имен побеждает over.
+func (s RequestSpec) Merge(over RequestSpec) RequestSpec {
+	if over.Method != "" {
+		s.Method = over.Method
+	}
+
+	if len(over.Headers) > 0 {
+		headers := make(map[string]string, len(s.Headers)+len(over.Headers))
+		for name, value := range s.Headers {
+			headers[http.CanonicalHeaderKey(name)] = value
+		}
+		for name, value := range over.Headers {
+			headers[http.CanonicalHeaderKey(name)] = value
+		}
+		s.Headers = headers
+	}
+
+	// Тело из over заменяет тело s целиком, в каком бы виде оно ни было задано
+	if over.Body != "" || over.BodyFile != "" {
+		s.Body = over.Body
+		s.BodyFile = over.BodyFile
+	}
+
+	if over.HeadFallback != nil {
+		s.HeadFallback = over.HeadFallback
+	}
+
+	return s
+}
+
+// Apply проверяет RequestSpec и заполняет параметры запроса цели.
+// Ошибки возвращаются как *types.FieldError.
+func (s RequestSpec) Apply(target *types.Target) error {
+	method := strings.ToUpper(s.Method)
+	if method != "" && !validToken(method) {
+		return &types.FieldError{Field: "method", Err: fmt.Errorf("invalid method %q", s.Method)}
+	}
+
+	if s.Body != "" && s.BodyFile != "" {
+		return &types.FieldError{Field: "body_file", Err: fmt.Errorf("body and body_file are mutually exclusive")}
+	}
+
+	var body []byte
+	switch {
+	case s.BodyFile != "":
+		data, err := os.ReadFile(s.BodyFile)
+		if err != nil {
+			return &types.FieldError{Field: "body_file", Err: err}
+		}
+		body = data
+	case s.Body != "":
+		body = []byte(s.Body)
+	}
+
+	// Как и curl -d, тело без явного метода отправляется POST-запросом
+	if method == "" && body != nil {
+		method = http.MethodPost
+	}
+
+	var headers http.Header
+	for name, value := range s.Headers {
+		if !validToken(name) {
+			return &types.FieldError{Field: "headers", Err: fmt.Errorf("invalid header name %q", name)}
+		}
+		if headers == nil {
+			headers = make(http.Header, len(s.Headers))
+		}
+		headers.Set(name, value)
+	}
+
+	target.Method = method
+	target.Headers = headers
+	target.Body = body
+	target.HeadFallback = s.HeadFallback != nil && *s.HeadFallback
+
+	return nil
+}
+
+// ParseHeader разбирает заголовок запроса вида "Name: value"
+func ParseHeader(s string) (string, string, error) {
+	name, value, ok := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+	if !ok || !validToken(name) {
+		return "", "", fmt.Errorf("invalid header %q, expected 'Name: value'", s)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// validToken проверяет строку на соответствие token из RFC 7230
+func validToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r > 127 || r <= 32 || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+	return true
+}
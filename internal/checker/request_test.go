@@ -0,0 +1,117 @@
+package checker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func TestRequestSpec_Merge(t *testing.T) {
+	fallback := true
+	base := RequestSpec{
+		Method:   "GET",
+		Headers:  map[string]string{"x-api-key": "default", "Accept": "text/html"},
+		BodyFile: "body.json",
+	}
+	over := RequestSpec{
+		Headers:      map[string]string{"X-Api-Key": "override"},
+		Body:         "inline",
+		HeadFallback: &fallback,
+	}
+
+	merged := base.Merge(over)
+
+	if merged.Method != "GET" {
+		t.Errorf("Expected method GET, got %q", merged.Method)
+	}
+	if merged.Headers["X-Api-Key"] != "override" || merged.Headers["Accept"] != "text/html" {
+		t.Errorf("Expected merged headers, got %v", merged.Headers)
+	}
+	if merged.Body != "inline" || merged.BodyFile != "" {
+		t.Errorf("Expected inline body to replace body file, got %q / %q", merged.Body, merged.BodyFile)
+	}
+	if merged.HeadFallback == nil || !*merged.HeadFallback {
+		t.Errorf("Expected head fallback to be set")
+	}
+	if len(base.Headers) != 2 || base.Headers["x-api-key"] != "default" {
+		t.Errorf("Expected base headers to stay unchanged, got %v", base.Headers)
+	}
+}
+
+func TestRequestSpec_Apply(t *testing.T) {
+	bodyFile := filepath.Join(t.TempDir(), "body.json")
+	if err := os.WriteFile(bodyFile, []byte(`{"a": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name           string
+		spec           RequestSpec
+		expectedMethod string
+		expectedBody   string
+	}{
+		{"empty", RequestSpec{}, "", ""},
+		{"lower case method", RequestSpec{Method: "head"}, "HEAD", ""},
+		{"body defaults to POST", RequestSpec{Body: "x"}, "POST", "x"},
+		{"explicit method with body", RequestSpec{Method: "PUT", Body: "x"}, "PUT", "x"},
+		{"body from file", RequestSpec{BodyFile: bodyFile}, "POST", `{"a": 1}`},
+	}
+
+	for _, tc := range testCases {
+		var target types.Target
+		if err := tc.spec.Apply(&target); err != nil {
+			t.Errorf("%s: expected no error, got %v", tc.name, err)
+			continue
+		}
+		if target.Method != tc.expectedMethod || string(target.Body) != tc.expectedBody {
+			t.Errorf("%s: expected %q %q, got %q %q",
+				tc.name, tc.expectedMethod, tc.expectedBody, target.Method, target.Body)
+		}
+	}
+}
+
+func TestRequestSpec_ApplyErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		spec          RequestSpec
+		expectedField string
+	}{
+		{"invalid method", RequestSpec{Method: "GE T"}, "method"},
+		{"body and file", RequestSpec{Body: "x", BodyFile: "y"}, "body_file"},
+		{"missing file", RequestSpec{BodyFile: filepath.Join(t.TempDir(), "missing")}, "body_file"},
+		{"invalid header", RequestSpec{Headers: map[string]string{"Bad Name": "x"}}, "headers"},
+	}
+
+	for _, tc := range testCases {
+		err := tc.spec.Apply(&types.Target{})
+
+		var fieldErr *types.FieldError
+		if !errors.As(err, &fieldErr) {
+			t.Errorf("%s: expected *types.FieldError, got %v", tc.name, err)
+			continue
+		}
+		if fieldErr.Field != tc.expectedField {
+			t.Errorf("%s: expected field %s, got %s", tc.name, tc.expectedField, fieldErr.Field)
+		}
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	name, value, err := ParseHeader("Authorization:  Bearer a:b ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "Authorization" || value != "Bearer a:b" {
+		t.Errorf("Expected Authorization / 'Bearer a:b', got %q / %q", name, value)
+	}
+
+	for _, s := range []string{"no-colon", ": value", "Bad Name: x"} {
+		if _, _, err := ParseHeader(s); err == nil || !strings.Contains(err.Error(), "Name: value") {
+			t.Errorf("Expected error for %q, got %v", s, err)
+		}
+	}
+}
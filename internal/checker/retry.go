@@ -0,0 +1,131 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// DefaultRetryStatuses - коды ответа, при которых повтор имеет смысл
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy описывает повторные попытки для временных сбоев.
+// Нулевое значение отключает повторы.
+type RetryPolicy struct {
+	// MaxRetries - число повторов после первой попытки
+	MaxRetries int
+	// BaseDelay - задержка перед первым повтором, далее удваивается
+	BaseDelay time.Duration
+	// MaxDelay ограничивает задержку, в том числе из Retry-After
+	MaxDelay time.Duration
+	// Jitter - доля задержки (0..1), на которую она случайно сокращается
+	Jitter float64
+	// Statuses - коды ответа, после которых выполняется повтор
+	Statuses []int
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		BaseDelay: 200 * time.Millisecond,
+		MaxDelay:  5 * time.Second,
+		Jitter:    0.2,
+		Statuses:  DefaultRetryStatuses,
+	}
+}
+
+// shouldRetry сообщает, является ли результат временным сбоем
+func (p RetryPolicy) shouldRetry(result *types.Result) bool {
+	if result.Error == nil {
+		return slices.Contains(p.Statuses, result.StatusCode)
+	}
+
+	var (
+		timeoutErr     ErrTimeout
+		networkErr     ErrNetwork
+		refusedErr     ErrConnectionRefused
+		resetErr       ErrConnectionReset
+		closedErr      ErrConnectionClosed
+		unreachableErr ErrHostUnreachable
+	)
+	return errors.As(result.Error, &timeoutErr) ||
+		errors.As(result.Error, &networkErr) ||
+		errors.As(result.Error, &refusedErr) ||
+		errors.As(result.Error, &resetErr) ||
+		errors.As(result.Error, &closedErr) ||
+		errors.As(result.Error, &unreachableErr)
+}
+
+// delay вычисляет паузу перед повтором номер attempt (с нуля).
+// Если сервер прислал Retry-After, используется он.
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return p.capDelay(retryAfter)
+	}
+
+	d := p.BaseDelay
+	for i := 0; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	d = p.capDelay(d)
+
+	if p.Jitter > 0 {
+		d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+	}
+
+	return d
+}
+
+func (p RetryPolicy) capDelay(d time.Duration) time.Duration {
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// parseRetryAfter разбирает заголовок Retry-After в секундах или HTTP-дате
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// sleepContext ждет d или отмены ctx; возвращает false при отмене
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,186 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	testCases := []struct {
+		attempt    int
+		retryAfter time.Duration
+		expected   time.Duration
+	}{
+		{0, 0, 100 * time.Millisecond},
+		{1, 0, 200 * time.Millisecond},
+		{3, 0, 800 * time.Millisecond},
+		{4, 0, time.Second},
+		{60, 0, time.Second},
+		{0, 500 * time.Millisecond, 500 * time.Millisecond},
+		{0, time.Minute, time.Second},
+	}
+
+	for _, tc := range testCases {
+		if got := p.delay(tc.attempt, tc.retryAfter); got != tc.expected {
+			t.Errorf("delay(%d, %v): expected %v, got %v", tc.attempt, tc.retryAfter, tc.expected, got)
+		}
+	}
+}
+
+func TestRetryPolicy_Jitter(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		d := p.delay(0, 0)
+		if d < 500*time.Millisecond || d > time.Second {
+			t.Fatalf("Expected delay between 500ms and 1s, got %v", d)
+		}
+	}
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	p := RetryPolicy{Statuses: []int{503}}
+
+	testCases := []struct {
+		result   types.Result
+		expected bool
+	}{
+		{types.Result{StatusCode: 200}, false},
+		{types.Result{StatusCode: 503}, true},
+		{types.Result{StatusCode: 500}, false},
+		{types.Result{Error: ErrTimeout{}}, true},
+		{types.Result{Error: ErrNetwork{}}, true},
+		{types.Result{Error: ErrOther{}}, false},
+		{types.Result{Error: ErrConnectionRefused{}}, true},
+		{types.Result{Error: ErrConnectionClosed{}}, true},
+		{types.Result{Error: ErrDNSFailed{}}, false},
+		{types.Result{Error: context.Canceled}, false},
+	}
+
+	for i, tc := range testCases {
+		if got := p.shouldRetry(&tc.result); got != tc.expected {
+			t.Errorf("Case %d: expected %v, got %v", i, tc.expected, got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{"Mon, 01 Jan 2024 12:00:10 GMT", 10 * time.Second},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0},
+	}
+
+	for _, tc := range testCases {
+		if got := parseRetryAfter(tc.value, now); got != tc.expected {
+			t.Errorf("parseRetryAfter(%q): expected %v, got %v", tc.value, tc.expected, got)
+		}
+	}
+}
+
+func TestHTTPChecker_RetriesTransientStatus(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	hc := NewHTTPChecker()
+	hc.Retry = RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  10 * time.Millisecond,
+		MaxDelay:   50 * time.Millisecond,
+		Statuses:   []int{http.StatusServiceUnavailable},
+	}
+
+	start := time.Now()
+	result := hc.Check(context.Background(), types.Target{URL: server.URL})
+
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("Expected final status 200, got %d", result.StatusCode)
+	}
+	if len(result.Attempts) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(result.Attempts))
+	}
+	// Время начала относится к первой попытке, а не к последней
+	if result.StartedAt.Before(start) || result.StartedAt.After(start.Add(result.Attempts[0].Duration)) {
+		t.Errorf("Expected check to start with the first attempt at %v, got %v", start, result.StartedAt)
+	}
+	if result.Attempts[0].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected first attempt 503, got %d", result.Attempts[0].StatusCode)
+	}
+}
+
+func TestHTTPChecker_RetryLimit(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	hc := NewHTTPChecker()
+	hc.Retry = RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, Statuses: DefaultRetryStatuses}
+
+	result := hc.Check(context.Background(), types.Target{URL: server.URL})
+
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("Expected 3 requests, got %d", got)
+	}
+	if result.StatusCode != http.StatusTooManyRequests || len(result.Attempts) != 3 {
+		t.Errorf("Unexpected result: status %d, %d attempts", result.StatusCode, len(result.Attempts))
+	}
+}
+
+func TestHTTPChecker_NoRetryByDefault(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	result := NewHTTPChecker().Check(context.Background(), types.Target{URL: server.URL})
+
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Expected 1 request, got %d", got)
+	}
+	if len(result.Attempts) != 1 {
+		t.Errorf("Expected 1 attempt, got %d", len(result.Attempts))
+	}
+}
+
+func TestHTTPChecker_NoRetryForUnsupportedScheme(t *testing.T) {
+	hc := NewHTTPChecker()
+	hc.Retry = RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, Statuses: DefaultRetryStatuses}
+
+	result := hc.Check(context.Background(), types.Target{URL: "ftp://example.com/"})
+
+	if got := ErrorClass(result.Error); got != ClassOther {
+		t.Errorf("Expected class %s, got %s (%v)", ClassOther, got, result.Error)
+	}
+	if len(result.Attempts) != 1 {
+		t.Errorf("Expected 1 attempt, got %d", len(result.Attempts))
+	}
+}
@@ -0,0 +1,63 @@
+package checker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// newTLSInfo переносит параметры TLS-соединения и цепочку сертификатов,
+// присланную сервером, в результат проверки
+func newTLSInfo(state *tls.ConnectionState) *types.TLSInfo {
+	info := &types.TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+	}
+
+	for _, cert := range state.PeerCertificates {
+		info.Certificates = append(info.Certificates, types.Certificate{
+			Subject:  cert.Subject.String(),
+			Issuer:   cert.Issuer.String(),
+			SANs:     certSANs(cert),
+			NotAfter: cert.NotAfter,
+		})
+	}
+
+	return info
+}
+
+func certSANs(cert *x509.Certificate) []string {
+	sans := append([]string(nil), cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
+
+// certWarnings возвращает предупреждения о сертификатах цепочки,
+// срок действия которых истекает раньше, чем через threshold
+func certWarnings(info *types.TLSInfo, threshold time.Duration, now time.Time) []string {
+	if info == nil || threshold <= 0 {
+		return nil
+	}
+
+	var warnings []string
+	for i, cert := range info.Certificates {
+		left := cert.NotAfter.Sub(now)
+		if left >= threshold {
+			continue
+		}
+
+		kind := "intermediate"
+		if i == 0 {
+			kind = "leaf"
+		}
+		warnings = append(warnings, fmt.Sprintf("%s certificate %s expires in %d days (%s)",
+			kind, cert.Subject, int(left.Hours()/24), cert.NotAfter.UTC().Format(time.DateOnly)))
+	}
+
+	return warnings
+}
@@ -0,0 +1,73 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func TestHTTPChecker_TLSInfo(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	hc := NewHTTPChecker()
+	hc.Transport = server.Client().Transport
+
+	result := hc.Check(context.Background(), types.Target{URL: server.URL})
+
+	if result.Error != nil {
+		t.Fatalf("Expected no error, got %v", result.Error)
+	}
+	if result.TLS == nil {
+		t.Fatal("Expected TLS info for https target")
+	}
+	if !strings.HasPrefix(result.TLS.Version, "TLS") || result.TLS.CipherSuite == "" {
+		t.Errorf("Expected TLS version and cipher, got %+v", result.TLS)
+	}
+
+	leaf := server.Certificate()
+	if len(result.TLS.Certificates) == 0 || !result.TLS.Certificates[0].NotAfter.Equal(leaf.NotAfter) {
+		t.Fatalf("Expected leaf certificate, got %+v", result.TLS.Certificates)
+	}
+	if !strings.Contains(strings.Join(result.TLS.Certificates[0].SANs, ","), "127.0.0.1") {
+		t.Errorf("Expected 127.0.0.1 in SANs, got %v", result.TLS.Certificates[0].SANs)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings without threshold, got %v", result.Warnings)
+	}
+
+	plain := NewHTTPChecker().Check(context.Background(), types.Target{URL: redirectServer(t).URL + "/c"})
+	if plain.TLS != nil {
+		t.Errorf("Expected no TLS info for http target, got %+v", plain.TLS)
+	}
+}
+
+func TestCertWarnings(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	info := &types.TLSInfo{Certificates: []types.Certificate{
+		{Subject: "CN=example.com", NotAfter: now.Add(90 * 24 * time.Hour)},
+		{Subject: "CN=Intermediate CA", NotAfter: now.Add(10 * 24 * time.Hour)},
+	}}
+
+	if w := certWarnings(info, 0, now); w != nil {
+		t.Errorf("Expected no warnings when disabled, got %v", w)
+	}
+
+	warnings := certWarnings(info, 30*24*time.Hour, now)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %v", warnings)
+	}
+	expected := "intermediate certificate CN=Intermediate CA expires in 10 days (2026-01-11)"
+	if warnings[0] != expected {
+		t.Errorf("Expected %q, got %q", expected, warnings[0])
+	}
+
+	if w := certWarnings(info, 100*24*time.Hour, now); len(w) != 2 || !strings.HasPrefix(w[0], "leaf") {
+		t.Errorf("Expected warnings for leaf and intermediate, got %v", w)
+	}
+}
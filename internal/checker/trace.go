@@ -0,0 +1,105 @@
+package checker
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// phaseTracer измеряет фазы запроса через httptrace. При редиректах
+// измерения начинаются заново, так что в итоге остаются фазы последнего
+// запроса цепочки.
+type phaseTracer struct {
+	// Обработчики httptrace могут вызываться из разных горутин,
+	// например при параллельном подключении к нескольким адресам
+	mu     sync.Mutex
+	timing types.Timing
+
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+}
+
+func (p *phaseTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.timing = types.Timing{}
+			p.firstByte = time.Time{}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.timing.ConnReused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.timing.DNS = time.Since(p.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.connectStart = time.Now()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if err == nil {
+				p.timing.Connect = time.Since(p.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.timing.TLS = time.Since(p.tlsStart)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.wroteRequest = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.firstByte = time.Now()
+			p.timing.TTFB = p.firstByte.Sub(p.wroteRequest)
+		},
+	}
+}
+
+// result возвращает измеренные фазы
+func (p *phaseTracer) result() types.Timing {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.timing
+}
+
+// finish возвращает фазы вместе с передачей тела, закончившейся в end.
+// Передача отсчитывается от первого байта ответа, чтобы не терять время
+// между ним и возвратом из client.Do.
+func (p *phaseTracer) finish(end time.Time) types.Timing {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	timing := p.timing
+	if !p.firstByte.IsZero() {
+		timing.Transfer = end.Sub(p.firstByte)
+	}
+	return timing
+}
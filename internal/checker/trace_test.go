@@ -0,0 +1,61 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func TestHTTPChecker_Timing(t *testing.T) {
+	const delay = 30 * time.Millisecond
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		fmt.Fprint(w, "first chunk")
+		w.(http.Flusher).Flush()
+		time.Sleep(delay)
+		fmt.Fprint(w, "second chunk")
+	}))
+	defer server.Close()
+
+	hc := NewHTTPChecker()
+	hc.Transport = server.Client().Transport
+
+	start := time.Now()
+	result := hc.Check(context.Background(), types.Target{URL: server.URL})
+	if result.Error != nil {
+		t.Fatalf("Expected no error, got %v", result.Error)
+	}
+
+	timing := result.Timing
+	if timing.ConnReused {
+		t.Error("Expected a new connection for the first request")
+	}
+	if timing.Connect <= 0 || timing.TLS <= 0 {
+		t.Errorf("Expected connect and TLS phases, got %+v", timing)
+	}
+	if timing.TTFB < delay {
+		t.Errorf("Expected TTFB of at least %v, got %v", delay, timing.TTFB)
+	}
+	// Первый байт приходит чуть позже, чем сервер начинает паузу,
+	// поэтому нижняя граница передачи взята с запасом
+	if timing.Transfer < delay/2 {
+		t.Errorf("Expected transfer of at least %v, got %v", delay/2, timing.Transfer)
+	}
+	if timing.TTFB+timing.Transfer > time.Since(start) {
+		t.Errorf("Expected phases to fit into the request, got %+v", timing)
+	}
+	if timing.DNS != 0 {
+		t.Errorf("Expected no DNS lookup for an IP address, got %v", timing.DNS)
+	}
+
+	reused := hc.Check(context.Background(), types.Target{URL: server.URL})
+	if !reused.Timing.ConnReused || reused.Timing.Connect != 0 || reused.Timing.TLS != 0 {
+		t.Errorf("Expected reused connection without connect phases, got %+v", reused.Timing)
+	}
+}
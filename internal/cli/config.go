@@ -2,10 +2,27 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/nashabanov/urlcheck/internal/baseline"
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/configfile"
+	"github.com/nashabanov/urlcheck/internal/crawl"
+	"github.com/nashabanov/urlcheck/internal/expect"
+	"github.com/nashabanov/urlcheck/internal/output"
+	"github.com/nashabanov/urlcheck/internal/types"
 )
 
 type Config struct {
+	// Mode - режим работы: ModeCheck, ModeWatch, ModeExporter или ModeCrawl
+	Mode string
+
+	ConfigFile string
+
 	File  string
 	URLs  string
 	Stdin bool
@@ -14,10 +31,88 @@ type Config struct {
 	Timeout time.Duration
 	MaxUrls int
 
-	Color bool
-	Quiet bool
+	MaxPerHost int
+	HostDelay  time.Duration
+	PerIP      bool
+
+	Rate  float64
+	Burst int
+
+	Retries       int
+	RetryDelay    time.Duration
+	RetryMaxDelay time.Duration
+	RetryJitter   float64
+	RetryOn       string
+
+	Redirects    string
+	MaxRedirects int
+
+	CertWarnDays int
+
+	Method       string
+	Headers      stringList
+	Body         string
+	BodyFile     string
+	HeadFallback bool
+
+	ExpectStatus    string
+	ExpectBody      string
+	ExpectBodyRegex string
+	RejectBody      string
+	RejectBodyRegex string
+	ExpectHeaders   stringList
+	MaxLatency      time.Duration
+	ExpectRedirects int
+	ExpectHTTPS     bool
+
+	Color   bool
+	Quiet   bool
+	Verbose bool
+	Output  string
+	// OutputFile - файл для структурированного вывода вместо stdout
+	OutputFile string
+	// Columns - колонки для вывода csv и tsv
+	Columns string
+	// Format и SummaryFormat - шаблоны text/template для строки результата и итогов
+	Format        string
+	SummaryFormat string
+	JUnit         string
+	// Textfile - файл для textfile collector из node_exporter
+	Textfile string
+
+	Interval time.Duration
+	Listen   string
+
+	// MaxDepth и SameDomain ограничивают обход ссылок в режиме crawl
+	MaxDepth   int
+	SameDomain bool
+
+	// Baseline - результаты предыдущего запуска в формате json или jsonl;
+	// LatencyRegression - рост задержки в процентах, считающийся регрессией
+	Baseline          string
+	LatencyRegression float64
+
+	// MaxFailures и MaxFailureRate - пороги отказов, -1 - порог не задан;
+	// CriticalTag - метка целей, отказ которых всегда проваливает запуск
+	MaxFailures    int
+	MaxFailureRate float64
+	CriticalTag    string
+
+	// FailFast останавливает проверку после первого отказа
+	FailFast bool
+
+	// Webhook - адрес для уведомлений о неуспешных проверках
+	Webhook         string
+	WebhookTemplate string
+	WebhookRetries  int
+	WebhookMute     time.Duration
 
 	Version bool
+
+	// setFlags - флаги, явно указанные в командной строке; они имеют
+	// приоритет над значениями из файла конфигурации
+	setFlags map[string]bool
+	file     *configfile.File
 }
 
 const (
@@ -25,6 +120,23 @@ const (
 	AppName    = "urlcheck"
 )
 
+// Режимы работы, задаются первым аргументом командной строки
+const (
+	// ModeCheck - однократная проверка, режим по умолчанию
+	ModeCheck = ""
+	// ModeWatch - периодическая проверка с выводом смены состояний
+	ModeWatch = "watch"
+	// ModeExporter - HTTP-сервер с пробами для Prometheus
+	ModeExporter = "exporter"
+	// ModeCrawl - проверка страниц и найденных на них ссылок
+	ModeCrawl = "crawl"
+)
+
+// singleRun сообщает, завершается ли режим итогами одного запуска
+func (c *Config) singleRun() bool {
+	return c.Mode == ModeCheck || c.Mode == ModeCrawl
+}
+
 func (c *Config) Validate() error {
 	if c.Version {
 		fmt.Printf("%s version %s", AppName, AppVersion)
@@ -42,8 +154,16 @@ func (c *Config) Validate() error {
 		sources++
 	}
 
-	if sources == 0 {
-		return fmt.Errorf("no URL source specified. Use -file, -urls, or -stdin")
+	// Экспортеру цели передает Prometheus в каждом запросе
+	if c.Mode == ModeExporter {
+		if sources > 0 {
+			return fmt.Errorf("exporter mode takes targets from /probe requests, not from -file, -urls or -stdin")
+		}
+		if c.Listen == "" {
+			return fmt.Errorf("listen address must not be empty")
+		}
+	} else if sources == 0 && (c.file == nil || len(c.file.Targets) == 0) {
+		return fmt.Errorf("no URL source specified. Use -file, -urls, -stdin or targets in -config")
 	}
 
 	if sources > 1 {
@@ -62,8 +182,159 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("")
 	}
 
-	if c.MaxUrls <= 0 {
-		return fmt.Errorf("")
+	if c.MaxUrls < 0 {
+		return fmt.Errorf("max-urls must not be negative")
+	}
+
+	if c.MaxPerHost < 0 {
+		return fmt.Errorf("max-per-host must not be negative")
+	}
+
+	if c.HostDelay < 0 {
+		return fmt.Errorf("host-delay must not be negative")
+	}
+
+	if c.Rate < 0 {
+		return fmt.Errorf("rate must not be negative")
+	}
+
+	if c.Burst < 1 {
+		return fmt.Errorf("burst must be at least 1")
+	}
+
+	if c.Retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+
+	if c.RetryDelay < 0 || c.RetryMaxDelay < 0 {
+		return fmt.Errorf("retry delays must not be negative")
+	}
+
+	if c.RetryJitter < 0 || c.RetryJitter > 1 {
+		return fmt.Errorf("retry jitter must be between 0 and 1")
+	}
+
+	if _, err := parseStatusList(c.RetryOn); err != nil {
+		return fmt.Errorf("invalid -retry-on: %w", err)
+	}
+
+	if !checker.ValidRedirectMode(c.Redirects) {
+		return fmt.Errorf("unknown redirect mode %q. Use follow, none or same-host", c.Redirects)
+	}
+
+	if c.MaxRedirects < 1 {
+		return fmt.Errorf("max-redirects must be at least 1")
+	}
+
+	if c.CertWarnDays < 0 {
+		return fmt.Errorf("cert-warn-days must not be negative")
+	}
+
+	if c.Baseline != "" && !c.singleRun() {
+		return fmt.Errorf("-baseline is not supported in %s mode", c.Mode)
+	}
+
+	if c.LatencyRegression < 0 {
+		return fmt.Errorf("latency-regression must not be negative")
+	}
+
+	if c.MaxFailures < -1 {
+		return fmt.Errorf("max-failures must not be negative (-1 disables it)")
+	}
+
+	if c.MaxFailureRate < -1 || c.MaxFailureRate > 100 {
+		return fmt.Errorf("max-failure-rate must be between 0 and 100 (-1 disables it)")
+	}
+
+	if c.FailFast {
+		if !c.singleRun() {
+			return fmt.Errorf("-fail-fast is not supported in %s mode", c.Mode)
+		}
+		// Пороги отказов теряют смысл, если проверка останавливается на первом
+		if c.MaxFailures >= 0 || c.MaxFailureRate >= 0 {
+			return fmt.Errorf("-fail-fast cannot be used with -max-failures or -max-failure-rate")
+		}
+	}
+
+	if c.Webhook != "" {
+		if c.Mode == ModeExporter {
+			return fmt.Errorf("-webhook is not supported in exporter mode")
+		}
+		if u, err := url.Parse(c.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid -webhook URL %q", c.Webhook)
+		}
+	}
+
+	if c.WebhookRetries < 0 {
+		return fmt.Errorf("webhook-retries must not be negative")
+	}
+
+	if c.WebhookMute < 0 {
+		return fmt.Errorf("webhook-mute must not be negative")
+	}
+
+	request, err := c.RequestSpec()
+	if err != nil {
+		return err
+	}
+	if err := request.Apply(&types.Target{}); err != nil {
+		return fmt.Errorf("invalid request flag: %w", err)
+	}
+
+	if _, err := c.ExpectSpec().Compile(); err != nil {
+		return fmt.Errorf("invalid expectation flag: %w", err)
+	}
+
+	switch c.Output {
+	case output.FormatText, output.FormatJSON, output.FormatJSONL, output.FormatCSV, output.FormatTSV:
+	default:
+		return fmt.Errorf("unknown output format %q. Use text, json, jsonl, csv or tsv", c.Output)
+	}
+
+	if c.OutputFile != "" && c.Output == output.FormatText {
+		return fmt.Errorf("-output-file requires -output json, jsonl, csv or tsv")
+	}
+
+	if _, err := output.ParseColumns(c.Columns); err != nil {
+		return fmt.Errorf("invalid -columns: %w", err)
+	}
+
+	if c.Format != "" || c.SummaryFormat != "" {
+		if c.Output != output.FormatText {
+			return fmt.Errorf("-format and -summary-format require -output text")
+		}
+		if !c.singleRun() {
+			return fmt.Errorf("-format and -summary-format are not supported in %s mode", c.Mode)
+		}
+		if _, err := output.NewTemplateWriter(io.Discard, output.Config{}, c.Format, c.SummaryFormat); err != nil {
+			return err
+		}
+	}
+
+	if c.Mode == ModeWatch {
+		if c.Interval <= 0 {
+			return fmt.Errorf("interval must be positive")
+		}
+		// В режиме watch нет итогового документа и отчета
+		if c.Output != output.FormatText && c.Output != output.FormatJSONL {
+			return fmt.Errorf("watch mode supports text and jsonl output only")
+		}
+		if c.JUnit != "" {
+			return fmt.Errorf("-junit is not supported in watch mode")
+		}
+	}
+
+	if c.Mode == ModeCrawl && c.MaxDepth < 0 {
+		return fmt.Errorf("max-depth must not be negative")
+	}
+
+	if c.Mode == ModeExporter && c.JUnit != "" {
+		return fmt.Errorf("-junit is not supported in exporter mode")
+	}
+
+	// Файл метрик описывает один завершенный запуск
+	if !c.singleRun() && c.Textfile != "" {
+		return fmt.Errorf("-textfile is not supported in %s mode", c.Mode)
 	}
 
 	return nil
@@ -73,8 +344,175 @@ func DefaultConfig() *Config {
 	return &Config{
 		Workers: 5,
 		Timeout: 5 * time.Second,
-		MaxUrls: 10000,
 		Color:   true,
-		Quiet:   false,
+		Burst:   1,
+
+		RetryDelay:    200 * time.Millisecond,
+		RetryMaxDelay: 5 * time.Second,
+		RetryJitter:   0.2,
+		RetryOn:       "429,502,503,504",
+
+		Redirects:    checker.RedirectFollow,
+		MaxRedirects: checker.DefaultMaxRedirects,
+
+		Interval: 30 * time.Second,
+		Listen:   ":9115",
+
+		MaxDepth:   crawl.DefaultMaxDepth,
+		SameDomain: true,
+
+		LatencyRegression: baseline.DefaultLatencyThreshold,
+
+		MaxFailures:    -1,
+		MaxFailureRate: -1,
+		CriticalTag:    "critical",
+
+		WebhookRetries: 2,
+
+		Quiet:  false,
+		Output: output.FormatText,
+	}
+}
+
+// LoadFile читает файл конфигурации и применяет его настройки
+// к параметрам, не указанным в командной строке
+func (c *Config) LoadFile() error {
+	file, err := configfile.Load(c.ConfigFile)
+	if err != nil {
+		return err
+	}
+	c.file = file
+
+	d := file.Defaults
+	applyDefault(c, "workers", d.Workers, &c.Workers)
+	applyDefault(c, "timeout", d.Timeout, &c.Timeout)
+	applyDefault(c, "max-urls", d.MaxURLs, &c.MaxUrls)
+	applyDefault(c, "max-per-host", d.MaxPerHost, &c.MaxPerHost)
+	applyDefault(c, "host-delay", d.HostDelay, &c.HostDelay)
+	applyDefault(c, "per-ip", d.PerIP, &c.PerIP)
+	applyDefault(c, "rate", d.Rate, &c.Rate)
+	applyDefault(c, "burst", d.Burst, &c.Burst)
+	applyDefault(c, "retries", d.Retries, &c.Retries)
+	applyDefault(c, "retry-delay", d.RetryDelay, &c.RetryDelay)
+	applyDefault(c, "retry-max-delay", d.RetryMaxDelay, &c.RetryMaxDelay)
+	applyDefault(c, "retry-jitter", d.RetryJitter, &c.RetryJitter)
+	applyDefault(c, "retry-on", d.RetryOn, &c.RetryOn)
+	applyDefault(c, "redirects", d.Redirects, &c.Redirects)
+	applyDefault(c, "max-redirects", d.MaxRedirects, &c.MaxRedirects)
+	applyDefault(c, "cert-warn-days", d.CertWarnDays, &c.CertWarnDays)
+	applyDefault(c, "interval", d.Interval, &c.Interval)
+	applyDefault(c, "listen", d.Listen, &c.Listen)
+	applyDefault(c, "max-depth", d.MaxDepth, &c.MaxDepth)
+	applyDefault(c, "same-domain", d.SameDomain, &c.SameDomain)
+	applyDefault(c, "baseline", d.Baseline, &c.Baseline)
+	applyDefault(c, "latency-regression", d.LatencyRegression, &c.LatencyRegression)
+	applyDefault(c, "max-failures", d.MaxFailures, &c.MaxFailures)
+	applyDefault(c, "max-failure-rate", d.MaxFailureRate, &c.MaxFailureRate)
+	applyDefault(c, "critical-tag", d.CriticalTag, &c.CriticalTag)
+	applyDefault(c, "fail-fast", d.FailFast, &c.FailFast)
+	applyDefault(c, "webhook", d.Webhook, &c.Webhook)
+	applyDefault(c, "webhook-template", d.WebhookTemplate, &c.WebhookTemplate)
+	applyDefault(c, "webhook-retries", d.WebhookRetries, &c.WebhookRetries)
+	applyDefault(c, "webhook-mute", d.WebhookMute, &c.WebhookMute)
+	applyDefault(c, "output", d.Output, &c.Output)
+	applyDefault(c, "output-file", d.OutputFile, &c.OutputFile)
+	applyDefault(c, "columns", d.Columns, &c.Columns)
+	applyDefault(c, "format", d.Format, &c.Format)
+	applyDefault(c, "summary-format", d.SummaryFormat, &c.SummaryFormat)
+	applyDefault(c, "junit", d.JUnit, &c.JUnit)
+	applyDefault(c, "textfile", d.Textfile, &c.Textfile)
+	applyDefault(c, "color", d.Color, &c.Color)
+	applyDefault(c, "quiet", d.Quiet, &c.Quiet)
+	applyDefault(c, "verbose", d.Verbose, &c.Verbose)
+
+	return nil
+}
+
+func applyDefault[T any](c *Config, flagName string, value *T, dst *T) {
+	if value != nil && !c.setFlags[flagName] {
+		*dst = *value
+	}
+}
+
+// hasSourceFlag сообщает, указан ли источник URL в командной строке
+func (c *Config) hasSourceFlag() bool {
+	return c.File != "" || c.URLs != "" || c.Stdin
+}
+
+// RequestSpec собирает параметры запроса, заданные флагами
+func (c *Config) RequestSpec() (checker.RequestSpec, error) {
+	spec := checker.RequestSpec{
+		Method:   c.Method,
+		Body:     c.Body,
+		BodyFile: c.BodyFile,
+	}
+
+	for _, h := range c.Headers {
+		name, value, err := checker.ParseHeader(h)
+		if err != nil {
+			return spec, fmt.Errorf("invalid -header: %w", err)
+		}
+		if spec.Headers == nil {
+			spec.Headers = map[string]string{}
+		}
+		spec.Headers[name] = value
+	}
+
+	if c.setFlags["head-fallback"] {
+		spec.HeadFallback = &c.HeadFallback
+	}
+
+	return spec, nil
+}
+
+// ExpectSpec собирает ожидания к ответу, заданные флагами
+func (c *Config) ExpectSpec() expect.Spec {
+	spec := expect.Spec{
+		Status:       c.ExpectStatus,
+		Headers:      c.ExpectHeaders,
+		MaxLatency:   c.MaxLatency,
+		MaxRedirects: c.ExpectRedirects,
+	}
+	if c.setFlags["expect-https"] {
+		spec.HTTPS = &c.ExpectHTTPS
+	}
+	if c.ExpectBody != "" {
+		spec.BodyContains = []string{c.ExpectBody}
+	}
+	if c.RejectBody != "" {
+		spec.BodyNotContains = []string{c.RejectBody}
+	}
+	if c.ExpectBodyRegex != "" {
+		spec.BodyMatches = []string{c.ExpectBodyRegex}
+	}
+	if c.RejectBodyRegex != "" {
+		spec.BodyNotMatches = []string{c.RejectBodyRegex}
+	}
+	return spec
+}
+
+// parseStatusList разбирает список HTTP-кодов через запятую
+func parseStatusList(s string) ([]int, error) {
+	var codes []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		code, err := strconv.Atoi(part)
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid status code %q", part)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// ExitPolicy возвращает политику кода выхода из конфигурации
+func (c *Config) ExitPolicy() ExitPolicy {
+	return ExitPolicy{
+		MaxFailures:    c.MaxFailures,
+		MaxFailureRate: c.MaxFailureRate,
+		CriticalTag:    c.CriticalTag,
 	}
 }
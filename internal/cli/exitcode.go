@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nashabanov/urlcheck/internal/output"
+)
+
+// Коды выхода
+const (
+	// ExitOK - все проверки успешны
+	ExitOK = 0
+	// ExitFailed - отказы превысили порог или выполнение завершилось ошибкой
+	ExitFailed = 1
+	// ExitUsage - неверные флаги или файл конфигурации
+	ExitUsage = 2
+	// ExitWarnings - отказы в пределах порога или предупреждения
+	ExitWarnings = 3
+	// ExitInterrupted - стандартный код для SIGINT
+	ExitInterrupted = 130
+)
+
+// UsageError - ошибка в параметрах запуска
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode возвращает код выхода для ошибки, которую вернул Execute
+func ExitCode(err error) int {
+	var usageErr *UsageError
+	if errors.As(err, &usageErr) {
+		return ExitUsage
+	}
+	return ExitFailed
+}
+
+// ExitPolicy определяет, при каких отказах запуск считается проваленным.
+// Если ни один порог не задан, проваливает любой отказ.
+type ExitPolicy struct {
+	// MaxFailures - допустимое число отказов, -1 - без ограничения
+	MaxFailures int
+	// MaxFailureRate - допустимая доля отказов в процентах, -1 - без ограничения
+	MaxFailureRate float64
+	// CriticalTag - метка целей, отказ любой из которых проваливает запуск
+	CriticalTag string
+}
+
+// Evaluate возвращает код выхода и причину провала для итогов запуска.
+// При сравнении с базовым запуском отказами считаются только регрессии:
+// уже известные отказы не меняют код выхода. Отказ цели с CriticalTag
+// проваливает запуск всегда, в том числе при сравнении с базовым запуском.
+func (p ExitPolicy) Evaluate(summary output.Summary) (int, string) {
+	failed := summary.Failed
+	what := "failed"
+	if summary.Baseline != "" {
+		failed = summary.Regressions()
+		what = "regressed"
+	}
+
+	if n := summary.FailedTags[p.CriticalTag]; p.CriticalTag != "" && n > 0 {
+		return ExitFailed, fmt.Sprintf("URLs tagged %s failed: %d", p.CriticalTag, n)
+	}
+
+	if summary.Stopped {
+		return ExitFailed, "stopped after first failure (-fail-fast)"
+	}
+
+	switch {
+	case p.MaxFailures < 0 && p.MaxFailureRate < 0:
+		if failed > 0 {
+			return ExitFailed, ""
+		}
+	case p.MaxFailures >= 0 && failed > p.MaxFailures:
+		return ExitFailed, fmt.Sprintf("%d URLs %s, more than the allowed %d", failed, what, p.MaxFailures)
+	case p.MaxFailureRate >= 0 && summary.Total > 0:
+		if rate := float64(failed) / float64(summary.Total) * 100; rate > p.MaxFailureRate {
+			return ExitFailed, fmt.Sprintf("%.1f%% of URLs %s, more than the allowed %g%%", rate, what, p.MaxFailureRate)
+		}
+	}
+
+	if failed > 0 || summary.Warnings > 0 {
+		return ExitWarnings, ""
+	}
+	return ExitOK, ""
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nashabanov/urlcheck/internal/output"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func TestExitPolicy_Evaluate(t *testing.T) {
+	off := ExitPolicy{MaxFailures: -1, MaxFailureRate: -1, CriticalTag: "critical"}
+	withMax := func(n int) ExitPolicy { p := off; p.MaxFailures = n; return p }
+	withRate := func(rate float64) ExitPolicy { p := off; p.MaxFailureRate = rate; return p }
+
+	regression := types.Change{URL: "http://a.com", Kind: types.ChangeFailing, Regression: true}
+
+	testCases := []struct {
+		name     string
+		policy   ExitPolicy
+		summary  output.Summary
+		expected int
+	}{
+		{"all ok", off, output.Summary{Total: 10, Success: 10}, ExitOK},
+		{"any failure by default", off, output.Summary{Total: 10, Success: 9, Failed: 1}, ExitFailed},
+		{"warnings only", off, output.Summary{Total: 10, Success: 10, Warnings: 1}, ExitWarnings},
+		{"within max failures", withMax(2), output.Summary{Total: 10, Success: 8, Failed: 2}, ExitWarnings},
+		{"above max failures", withMax(2), output.Summary{Total: 10, Success: 7, Failed: 3}, ExitFailed},
+		{"within failure rate", withRate(20), output.Summary{Total: 10, Success: 8, Failed: 2}, ExitWarnings},
+		{"above failure rate", withRate(20), output.Summary{Total: 10, Success: 7, Failed: 3}, ExitFailed},
+		{
+			"critical failure below threshold", withRate(50),
+			output.Summary{Total: 10, Success: 9, Failed: 1, FailedTags: map[string]int{"critical": 1}},
+			ExitFailed,
+		},
+		{
+			"other tags do not matter", withRate(50),
+			output.Summary{Total: 10, Success: 9, Failed: 1, FailedTags: map[string]int{"api": 1}},
+			ExitWarnings,
+		},
+		{
+			"stopped by fail-fast", withRate(50),
+			output.Summary{Total: 2, Success: 1, Failed: 1, Stopped: true, Skipped: 8},
+			ExitFailed,
+		},
+		{
+			"known failures with baseline", off,
+			output.Summary{Total: 10, Success: 8, Failed: 2, Baseline: "before.json"},
+			ExitOK,
+		},
+		{
+			"warnings with baseline", off,
+			output.Summary{Total: 10, Success: 10, Warnings: 1, Baseline: "before.json"},
+			ExitWarnings,
+		},
+		{
+			"regression within threshold with baseline", withMax(1),
+			output.Summary{Total: 10, Success: 9, Failed: 1, Baseline: "before.json", Changes: []types.Change{regression}},
+			ExitWarnings,
+		},
+		{
+			"known critical failure with baseline", off,
+			output.Summary{Total: 10, Success: 9, Failed: 1, Baseline: "before.json", FailedTags: map[string]int{"critical": 1}},
+			ExitFailed,
+		},
+		{
+			"regression with baseline", off,
+			output.Summary{Total: 10, Success: 9, Failed: 1, Baseline: "before.json", Changes: []types.Change{regression}},
+			ExitFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got, _ := tc.policy.Evaluate(tc.summary); got != tc.expected {
+			t.Errorf("%s: expected exit code %d, got %d", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := ExitCode(&UsageError{Err: errors.New("bad flag")}); got != ExitUsage {
+		t.Errorf("Expected %d for usage error, got %d", ExitUsage, got)
+	}
+	if got := ExitCode(errors.New("no URLs found to check")); got != ExitFailed {
+		t.Errorf("Expected %d for runtime error, got %d", ExitFailed, got)
+	}
+}
@@ -3,13 +3,37 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"os"
+	"strings"
 )
 
-// ParseFlags парсит аргументы командной строки
+// stringList - флаг, который можно указать несколько раз
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// ParseFlags парсит аргументы командной строки. Первым аргументом
+// может быть указан режим работы, например watch.
 func ParseFlags() (*Config, error) {
 	config := DefaultConfig()
 
+	args := os.Args[1:]
+	if len(args) > 0 && (args[0] == ModeWatch || args[0] == ModeExporter || args[0] == ModeCrawl) {
+		config.Mode = args[0]
+		args = args[1:]
+	}
+
 	// Определяем флаги
+	flag.StringVar(&config.ConfigFile, "config", config.ConfigFile,
+		"YAML or JSON file with defaults and targets")
+
 	flag.StringVar(&config.File, "file", config.File,
 		"File containing URLs (one per line)")
 	flag.StringVar(&config.URLs, "urls", config.URLs,
@@ -22,12 +46,118 @@ func ParseFlags() (*Config, error) {
 	flag.DurationVar(&config.Timeout, "timeout", config.Timeout,
 		"Request timeout (e.g., 5s, 1m)")
 	flag.IntVar(&config.MaxUrls, "max-urls", config.MaxUrls,
-		"Maximum number of URLs to process")
+		"Maximum number of URLs to process (0 - no limit)")
+
+	flag.IntVar(&config.MaxPerHost, "max-per-host", config.MaxPerHost,
+		"Maximum concurrent requests per host (0 - no limit)")
+	flag.DurationVar(&config.HostDelay, "host-delay", config.HostDelay,
+		"Minimum delay between requests to the same host")
+	flag.BoolVar(&config.PerIP, "per-ip", config.PerIP,
+		"Apply per-host limits to resolved IP addresses instead of host names")
+	flag.Float64Var(&config.Rate, "rate", config.Rate,
+		"Maximum URL checks per second across all workers (0 - no limit)")
+	flag.IntVar(&config.Burst, "burst", config.Burst,
+		"Number of checks allowed to start at once above -rate")
+
+	flag.IntVar(&config.Retries, "retries", config.Retries,
+		"Number of retries for transient failures")
+	flag.DurationVar(&config.RetryDelay, "retry-delay", config.RetryDelay,
+		"Delay before the first retry, doubled on each next one")
+	flag.DurationVar(&config.RetryMaxDelay, "retry-max-delay", config.RetryMaxDelay,
+		"Maximum delay between retries, including Retry-After")
+	flag.Float64Var(&config.RetryJitter, "retry-jitter", config.RetryJitter,
+		"Random fraction (0..1) subtracted from each retry delay")
+	flag.StringVar(&config.RetryOn, "retry-on", config.RetryOn,
+		"Comma-separated status codes to retry")
+
+	flag.StringVar(&config.Method, "method", config.Method,
+		"HTTP method (default: GET, or POST when a body is given)")
+	flag.Var(&config.Headers, "header",
+		"Request header as 'Name: value' (repeatable)")
+	flag.StringVar(&config.Body, "body", config.Body,
+		"Request body")
+	flag.StringVar(&config.BodyFile, "body-file", config.BodyFile,
+		"File with request body")
+	flag.BoolVar(&config.HeadFallback, "head-fallback", config.HeadFallback,
+		"Retry HEAD requests with GET when the server answers 405 or 501")
+	flag.StringVar(&config.Redirects, "redirects", config.Redirects,
+		"Redirect policy: follow, none or same-host")
+	flag.IntVar(&config.MaxRedirects, "max-redirects", config.MaxRedirects,
+		"Maximum redirects to follow before failing")
+	flag.IntVar(&config.CertWarnDays, "cert-warn-days", config.CertWarnDays,
+		"Warn when a certificate in the chain expires within this many days")
+
+	flag.StringVar(&config.ExpectStatus, "expect-status", config.ExpectStatus,
+		"Accepted status codes, e.g. 2xx,301,400-404 (default: 2xx)")
+	flag.StringVar(&config.ExpectBody, "expect-body", config.ExpectBody,
+		"Substring the response body must contain")
+	flag.StringVar(&config.ExpectBodyRegex, "expect-body-regex", config.ExpectBodyRegex,
+		"Regular expression the response body must match")
+	flag.StringVar(&config.RejectBody, "reject-body", config.RejectBody,
+		"Substring the response body must not contain")
+	flag.StringVar(&config.RejectBodyRegex, "reject-body-regex", config.RejectBodyRegex,
+		"Regular expression the response body must not match")
+	flag.Var(&config.ExpectHeaders, "expect-header",
+		"Required header as 'Name' or 'Name: regexp' (repeatable)")
+	flag.DurationVar(&config.MaxLatency, "max-latency", config.MaxLatency,
+		"Maximum acceptable response time")
+	flag.IntVar(&config.ExpectRedirects, "expect-max-redirects", config.ExpectRedirects,
+		"Maximum acceptable redirect chain length")
+	flag.BoolVar(&config.ExpectHTTPS, "expect-https", config.ExpectHTTPS,
+		"Require the response to come over https without downgrades in redirects")
 
 	flag.BoolVar(&config.Color, "color", config.Color,
 		"Enable colored output")
 	flag.BoolVar(&config.Quiet, "quiet", config.Quiet,
 		"Quiet mode - show errors only")
+	flag.BoolVar(&config.Verbose, "verbose", config.Verbose,
+		"Show DNS, connect, TLS, time-to-first-byte and transfer times for each URL")
+	flag.StringVar(&config.Output, "output", config.Output,
+		"Output format: text, json, jsonl, csv or tsv")
+	flag.StringVar(&config.OutputFile, "output-file", config.OutputFile,
+		"Write json, jsonl, csv or tsv output to file instead of stdout")
+	flag.StringVar(&config.Columns, "columns", config.Columns,
+		"Comma-separated columns for csv and tsv output")
+	flag.StringVar(&config.Format, "format", config.Format,
+		"Go template for each result line, e.g. '{{.URL}} {{.StatusCode}}'")
+	flag.StringVar(&config.SummaryFormat, "summary-format", config.SummaryFormat,
+		"Go template for the summary, e.g. '{{.Success}}/{{.Total}} ok'")
+	flag.StringVar(&config.JUnit, "junit", config.JUnit,
+		"Write JUnit XML report to file")
+	flag.StringVar(&config.Textfile, "textfile", config.Textfile,
+		"Write Prometheus metrics to file for the node_exporter textfile collector")
+
+	flag.DurationVar(&config.Interval, "interval", config.Interval,
+		"Check interval in watch mode")
+	flag.StringVar(&config.Listen, "listen", config.Listen,
+		"Address to listen on in exporter mode")
+	flag.IntVar(&config.MaxDepth, "max-depth", config.MaxDepth,
+		"Maximum number of links followed from the seed pages in crawl mode")
+	flag.BoolVar(&config.SameDomain, "same-domain", config.SameDomain,
+		"Crawl only pages on the seed hosts; other links are checked but not crawled")
+
+	flag.StringVar(&config.Baseline, "baseline", config.Baseline,
+		"Compare with results of a previous run saved with -output json or jsonl")
+	flag.Float64Var(&config.LatencyRegression, "latency-regression", config.LatencyRegression,
+		"Latency increase in percent reported as a regression with -baseline")
+
+	flag.IntVar(&config.MaxFailures, "max-failures", config.MaxFailures,
+		"Fail only if more URLs than this fail (-1 - off)")
+	flag.Float64Var(&config.MaxFailureRate, "max-failure-rate", config.MaxFailureRate,
+		"Fail only if more than this percent of URLs fail (-1 - off)")
+	flag.StringVar(&config.CriticalTag, "critical-tag", config.CriticalTag,
+		"Tag of -config targets whose failure always fails the run")
+	flag.BoolVar(&config.FailFast, "fail-fast", config.FailFast,
+		"Stop checking after the first failed URL")
+
+	flag.StringVar(&config.Webhook, "webhook", config.Webhook,
+		"URL to POST a JSON notification to for each failed check")
+	flag.StringVar(&config.WebhookTemplate, "webhook-template", config.WebhookTemplate,
+		"File with a Go template for the webhook payload")
+	flag.IntVar(&config.WebhookRetries, "webhook-retries", config.WebhookRetries,
+		"Retries for webhook network errors, 429 and 5xx responses")
+	flag.DurationVar(&config.WebhookMute, "webhook-mute", config.WebhookMute,
+		"Do not notify about the same URL again within this time")
 
 	flag.BoolVar(&config.Version, "version", config.Version,
 		"Show version information")
@@ -37,8 +167,14 @@ func ParseFlags() (*Config, error) {
 		printUsage()
 	}
 
-	// Парсим
-	flag.Parse()
+	// Парсим; при ошибке flag.CommandLine сам выводит справку и завершает работу
+	_ = flag.CommandLine.Parse(args)
+
+	// Запоминаем явно указанные флаги: они важнее файла конфигурации
+	config.setFlags = map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		config.setFlags[f.Name] = true
+	})
 
 	return config, nil
 }
@@ -49,20 +185,109 @@ func printUsage() {
 
 Usage:
   %s [options]
+  %s watch [options]    Re-check URLs periodically and report UP/DOWN changes
+  %s exporter [options] Serve /probe and /metrics for Prometheus
+  %s crawl [options]    Check pages and every link found on them
 
 Data Sources (choose exactly one):
   -file string       File containing URLs (one per line)
   -urls string       Comma-separated list of URLs
   -stdin             Read URLs from stdin
 
+Configuration:
+  -config string     YAML or JSON file with defaults and targets;
+                     command line flags override its values
+
 Options:
   -workers int       Number of concurrent workers (default: 10)
   -timeout duration  Request timeout, e.g. 5s, 1m (default: 5s)  
-  -max-urls int      Maximum URLs to process (default: 10000)
+  -max-urls int      Maximum URLs to process (default: 0, no limit)
+
+Politeness:
+  -max-per-host int        Maximum concurrent requests per host (default: 0, no limit)
+  -host-delay duration     Minimum delay between requests to the same host
+  -per-ip                  Apply per-host limits to resolved IP addresses
+  -rate float              Maximum URL checks per second across all workers (default: 0, no limit);
+                           retries of a check are not counted separately
+  -burst int               Checks allowed to start at once above -rate (default: 1)
+
+Retries:
+  -retries int               Retries for timeouts, network errors and -retry-on codes (default: 0)
+  -retry-delay duration      Delay before the first retry, doubled each time (default: 200ms)
+  -retry-max-delay duration  Upper bound for retry delay and Retry-After (default: 5s)
+  -retry-jitter float        Random fraction subtracted from each delay (default: 0.2)
+  -retry-on string           Status codes to retry (default: 429,502,503,504)
+
+Request:
+  -method string             HTTP method (default: GET, or POST when a body is given)
+  -header string             Request header, 'Name: value' (repeatable)
+  -body string               Request body
+  -body-file string          File with request body
+  -head-fallback             Retry HEAD with GET when the server answers 405 or 501
+  -redirects string          Redirect policy: follow, none, same-host (default: follow)
+  -max-redirects int         Maximum redirects to follow before failing (default: 10)
+  -cert-warn-days int        Warn when a certificate in the chain expires within N days (default: 0, off)
+
+Expectations:
+  -expect-status string      Accepted status codes, e.g. 2xx,301,400-404 (default: 2xx)
+  -expect-body string        Substring the response body must contain
+  -expect-body-regex string  Regular expression the response body must match
+  -reject-body string        Substring the response body must not contain
+  -reject-body-regex string  Regular expression the response body must not match
+  -expect-header string      Required header, 'Name' or 'Name: regexp' (repeatable)
+  -max-latency duration      Maximum acceptable response time
+  -expect-max-redirects int  Maximum acceptable redirect chain length
+  -expect-https              Require https at the end of redirects, with no downgrade on the way
+
+Output:
   -color             Enable colored output (default: true)
   -quiet             Quiet mode - show errors only (default: false)
+  -verbose           Show request phase timings for each URL
+  -output string     Output format: text, json, jsonl, csv, tsv (default: text)
+  -output-file string  Write json, jsonl, csv or tsv output to file instead of stdout
+  -columns string    Columns for csv and tsv: url, method, status, duration_ms, success,
+                     error_class, error, final_url, content_length, timestamp,
+                     referrer
+                     (default: url,status,duration_ms,error_class,final_url,content_length,timestamp)
+  -format string     Go template for each result line over the check result,
+                     e.g. '{{pad 40 .URL}} {{.StatusCode}} {{ms .Duration}}ms';
+                     helpers: ms, color, json, pad, padLeft, errorClass
+  -summary-format string  Go template for the summary, e.g. '{{.Failed}} of {{.Total}} failed'
+  -junit string      Write JUnit XML report to file
+  -textfile string   Write Prometheus metrics to file (node_exporter textfile collector)
   -version           Show version information
 
+Watch:
+  -interval duration Check interval; targets in -config may set their own (default: 30s)
+
+Exporter:
+  -listen string     Address to listen on (default: :9115)
+
+Crawl:
+  -max-depth int     Links followed from the seed pages; 1 checks links on the seeds
+                     without crawling further (default: 1)
+  -same-domain       Crawl only pages on the seed hosts, links elsewhere are checked
+                     but not crawled (default: true)
+  -max-urls int      Also limits the number of links checked
+
+Exit Policy:
+  -max-failures int          Fail only if more than N URLs fail (default: off, any failure fails)
+  -max-failure-rate float    Fail only if more than N%% of URLs fail (default: off)
+  -critical-tag string       Targets in -config with this tag fail the run whenever they fail
+                             (default: critical)
+  -fail-fast                 Stop after the first failed URL, cancelling checks in progress
+
+Baseline:
+  -baseline string           Compare with a previous run saved with -output json or jsonl;
+                             the exit code reflects regressions only
+  -latency-regression float  Latency increase in percent counted as a regression (default: 50)
+
+Notifications:
+  -webhook string            URL to POST a JSON notification to for each failed check
+  -webhook-template string   File with a Go template for the payload (default: Slack/Teams compatible)
+  -webhook-retries int       Retries for network errors, 429 and 5xx (default: 2)
+  -webhook-mute duration     Do not notify about the same URL again within this time
+
 Examples:
   # Check URLs from command line
   %s -urls "https://google.com,https://github.com"
@@ -76,10 +301,61 @@ Examples:
   # Quiet mode without colors (good for scripts)
   %s -file urls.txt -color=false -quiet
 
+  # Accept redirects and auth challenges, require a fast healthy body
+  %s -file urls.txt -expect-status 2xx,301,401 -expect-body ok -max-latency 500ms
+
+  # Crawl a single site politely
+  %s -file urls.txt -workers 50 -max-per-host 2 -host-delay 100ms
+
+  # Cheap HEAD checks with an API key
+  %s -file urls.txt -method HEAD -head-fallback -header "X-Api-Key: secret"
+
+  # Warn about certificates expiring within a month
+  %s -file urls.txt -cert-warn-days 30
+
+  # Report outages and recoveries, checking every minute
+  %s watch -file urls.txt -interval 1m
+
+  # Post outages to Slack at most once per hour per URL
+  %s watch -file urls.txt -webhook https://hooks.slack.com/services/... -webhook-mute 1h
+
+  # Let Prometheus probe URLs: /probe?target=https://example.com
+  %s exporter -listen :9115 -timeout 10s
+
+  # Find broken links on a docs site, three clicks deep
+  %s crawl -urls https://docs.example.com -max-depth 3 -max-per-host 4
+
+  # Tolerate up to 5%% flaky URLs, but never a critical one
+  %s -config urlcheck.yaml -max-failure-rate 5
+
+  # Smoke test that stops at the first broken URL
+  %s -file urls.txt -fail-fast
+
+  # What changed after a deploy
+  %s -file urls.txt -output json > before.json
+  %s -file urls.txt -baseline before.json
+
+  # Check targets described in a config file with more workers
+  %s -config urlcheck.yaml -workers 50
+
+  # Publish results as a CI test report
+  %s -file urls.txt -junit report.xml
+
+  # Export results of a cron run to node_exporter
+  %s -file urls.txt -quiet -textfile /var/lib/node_exporter/urlcheck.prom
+
+  # Failed URLs as a spreadsheet
+  %s -file urls.txt -output csv -columns url,status,error -output-file report.csv
+
+  # Stream results as JSON Lines into jq
+  %s -file urls.txt -output jsonl | jq 'select(.success == false)'
+
 Exit Codes:
   0  All URLs successful
-  1  Some URLs failed or error occurred
+  1  Failures exceed the exit policy (with -baseline: regressions), or an error occurred
+  2  Invalid flags or configuration file
+  3  Only failures tolerated by the exit policy, or warnings
   130 Interrupted by user (Ctrl+C)
 
-`, AppName, AppName, AppName, AppName, AppName, AppName)
+`, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName)
 }
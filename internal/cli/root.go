@@ -2,18 +2,28 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"iter"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/nashabanov/urlcheck/internal/baseline"
 	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/crawl"
+	"github.com/nashabanov/urlcheck/internal/expect"
+	"github.com/nashabanov/urlcheck/internal/exporter"
 	"github.com/nashabanov/urlcheck/internal/input"
+	"github.com/nashabanov/urlcheck/internal/notify"
 	"github.com/nashabanov/urlcheck/internal/output"
 	"github.com/nashabanov/urlcheck/internal/types"
+	"github.com/nashabanov/urlcheck/internal/watch"
 	"github.com/nashabanov/urlcheck/internal/worker"
 )
 
@@ -23,13 +33,19 @@ func Execute() error {
 		return err
 	}
 
+	if config.ConfigFile != "" && !config.Version {
+		if err := config.LoadFile(); err != nil {
+			return &UsageError{Err: err}
+		}
+	}
+
 	if err := config.Validate(); err != nil {
 		if err.Error() == "version displayed" {
 			os.Exit(0) // Нормальный выход для -version
 		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		flag.Usage()
-		return err
+		return &UsageError{Err: err}
 	}
 
 	return runApplication(config)
@@ -39,27 +55,115 @@ func runApplication(config *Config) error {
 	ctx, cancel := setupGracefulShutdown()
 	defer cancel()
 
-	urls, err := getURLs(config)
+	if config.Mode == ModeExporter {
+		return executeExporter(ctx, config)
+	}
+
+	source, err := openTargets(config)
 	if err != nil {
-		return fmt.Errorf("failed to get URLs: %w", err)
+		return err
 	}
+	defer source.Close()
 
-	if len(urls) == 0 {
-		return fmt.Errorf("no URLs found to check")
+	if config.Mode == ModeWatch {
+		return executeWatch(ctx, config, source)
 	}
+	return executeURLCheck(ctx, config, source)
+}
 
-	return executeURLCheck(ctx, config, urls)
+// targetSource - поток целей для проверки
+type targetSource struct {
+	targets iter.Seq[types.Target]
+	// total - число целей, 0 если оно заранее неизвестно
+	total  int
+	reader *input.Reader
 }
 
-func getURLs(config *Config) ([]string, error) {
-	var urls []string
+// Err возвращает ошибку чтения источника после обхода целей
+func (s *targetSource) Err() error {
+	if s.reader == nil {
+		return nil
+	}
+	return s.reader.Err()
+}
+
+func (s *targetSource) Close() error {
+	if s.reader == nil {
+		return nil
+	}
+	return s.reader.Close()
+}
+
+// openTargets берет цели из источника, указанного флагами, а если его нет -
+// из файла конфигурации
+func openTargets(config *Config) (*targetSource, error) {
+	if config.file != nil && !config.hasSourceFlag() {
+		targets, err := fileTargets(config)
+		if err != nil {
+			return nil, err
+		}
+		return &targetSource{targets: slices.Values(targets), total: len(targets)}, nil
+	}
+
+	template, err := targetTemplate(config)
+	if err != nil {
+		return nil, err
+	}
 
+	var urls []string
 	if config.URLs != "" {
 		urls = parseURLString(config.URLs)
 	}
 
-	inputConfig := input.NewConfig(config.File, urls, config.Stdin, config.MaxUrls)
-	return inputConfig.GetURLs()
+	reader, err := input.NewConfig(config.File, urls, config.Stdin, config.MaxUrls).Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URLs: %w", err)
+	}
+
+	targets := func(yield func(types.Target) bool) {
+		for u := range reader.URLs() {
+			target := template
+			target.URL = u
+			if !yield(target) {
+				return
+			}
+		}
+	}
+
+	return &targetSource{targets: targets, total: reader.Total(), reader: reader}, nil
+}
+
+// fileTargets возвращает цели из файла конфигурации с учетом флагов
+func fileTargets(config *Config) ([]types.Target, error) {
+	request, err := config.RequestSpec()
+	if err != nil {
+		return nil, err
+	}
+	return config.file.TargetList(request, config.ExpectSpec())
+}
+
+// targetTemplate возвращает параметры запроса и ожидания, общие для всех
+// URL, которые не описаны в файле конфигурации
+func targetTemplate(config *Config) (types.Target, error) {
+	var template types.Target
+
+	request, err := config.RequestSpec()
+	if err != nil {
+		return template, err
+	}
+
+	spec := config.ExpectSpec()
+	if config.file != nil {
+		request = config.file.Defaults.Request.Merge(request)
+		spec = config.file.Defaults.Expect.Merge(spec)
+	}
+
+	if err := request.Apply(&template); err != nil {
+		return template, err
+	}
+
+	template.Expect, err = spec.Compile()
+	return template, err
 }
 
 func parseURLString(urlStr string) []string {
@@ -91,87 +195,418 @@ func setupGracefulShutdown() (context.Context, context.CancelFunc) {
 	return ctx, cancel
 }
 
-func executeURLCheck(ctx context.Context, config *Config, urls []string) error {
-	// Создаем компоненты
-	workerInstance := &worker.Worker{MaxWorkers: config.Workers}
+// newWorker создает пул воркеров с лимитами из конфигурации
+func newWorker(config *Config) *worker.Worker {
+	return &worker.Worker{
+		MaxWorkers: config.Workers,
+		MaxPerHost: config.MaxPerHost,
+		HostDelay:  config.HostDelay,
+		PerIP:      config.PerIP,
+		Rate:       config.Rate,
+		Burst:      config.Burst,
+		FailFast:   config.FailFast,
+	}
+}
 
+// newHTTPChecker создает HTTPChecker с политиками из конфигурации
+func newHTTPChecker(config *Config) (*checker.HTTPChecker, error) {
 	httpChecker := checker.NewHTTPChecker()
 	httpChecker.Timeout = config.Timeout
 
-	outputWriter := output.NewWriter(output.Config{
-		ColorOutput: config.Color && !config.Quiet,
-	})
+	retryStatuses, err := parseStatusList(config.RetryOn)
+	if err != nil {
+		return nil, err
+	}
+	httpChecker.Retry = checker.RetryPolicy{
+		MaxRetries: config.Retries,
+		BaseDelay:  config.RetryDelay,
+		MaxDelay:   config.RetryMaxDelay,
+		Jitter:     config.RetryJitter,
+		Statuses:   retryStatuses,
+	}
+	httpChecker.Redirect = checker.RedirectPolicy{
+		Mode: config.Redirects,
+		Max:  config.MaxRedirects,
+	}
+	httpChecker.CertWarn = time.Duration(config.CertWarnDays) * 24 * time.Hour
 
-	// Сбор результатов для итоговой статистики
-	var allResults []types.Result
-	startTime := time.Now()
+	return httpChecker, nil
+}
 
-	// Показываем начальное сообщение
-	if !config.Quiet {
-		fmt.Printf("Checking %d URLs with %d workers (timeout: %v)...\n",
-			len(urls), config.Workers, config.Timeout)
+// newWebhook создает notifier из конфигурации; nil, если вебхук не задан.
+// Шаблон проверяется сразу, до отправки запросов к целям.
+func newWebhook(config *Config) (*notify.Webhook, error) {
+	if config.Webhook == "" {
+		return nil, nil
 	}
 
-	// Выполняем проверку с callback'ом
-	err := workerInstance.Run(ctx, httpChecker, urls, func(current, total int, result *types.Result) {
-		if !config.Quiet {
-			outputWriter.WriteProgress(current, total, *result)
+	hook := notify.NewWebhook(config.Webhook)
+	hook.Retries = config.WebhookRetries
+	hook.Mute = config.WebhookMute
+
+	if config.WebhookTemplate != "" {
+		data, err := os.ReadFile(config.WebhookTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read webhook template: %w", err)
 		}
-		allResults = append(allResults, *result)
-	})
+		if hook.Template, err = notify.ParseTemplate(string(data)); err != nil {
+			return nil, fmt.Errorf("invalid webhook template: %w", err)
+		}
+	}
+
+	if err := hook.Validate(); err != nil {
+		return nil, err
+	}
+
+	return hook, nil
+}
 
-	// Обрабатываем ошибки выполнения
+// runChecks проверяет цели источника, а в режиме crawl - еще и ссылки,
+// найденные на страницах
+func runChecks(
+	ctx context.Context,
+	config *Config,
+	source *targetSource,
+	c checker.Checker,
+	callback func(current, total int, result *types.Result),
+) error {
+	workerInstance := newWorker(config)
+	if config.Mode != ModeCrawl {
+		return workerInstance.RunStream(ctx, c, source.targets, source.total, callback)
+	}
+
+	template, err := targetTemplate(config)
 	if err != nil {
+		return err
+	}
+
+	crawler := &crawl.Crawler{
+		Checker:    c,
+		Worker:     workerInstance,
+		Template:   template,
+		MaxDepth:   config.MaxDepth,
+		SameDomain: config.SameDomain,
+		MaxURLs:    config.MaxUrls,
+	}
+	err = crawler.Run(ctx, slices.Collect(source.targets), callback)
+	if n := crawler.Dropped(); n > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: -max-urls reached, %d discovered links not checked\n", n)
+	}
+	return err
+}
+
+func executeURLCheck(ctx context.Context, config *Config, source *targetSource) error {
+	// Создаем компоненты
+	httpChecker, err := newHTTPChecker(config)
+	if err != nil {
+		return err
+	}
+
+	out, closeOutput, err := openOutput(config)
+	if err != nil {
+		return err
+	}
+	defer closeOutput()
+	outputWriter := newResultWriter(config, out)
+
+	webhook, err := newWebhook(config)
+	if err != nil {
+		return err
+	}
+
+	var base *baseline.Baseline
+	if config.Baseline != "" {
+		if base, err = baseline.Load(config.Baseline); err != nil {
+			return err
+		}
+		base.LatencyThreshold = config.LatencyRegression
+	}
+
+	// Отчеты в файлы получают все результаты независимо от quiet
+	var reports []output.ResultWriter
+	if config.JUnit != "" {
+		junitFile, err := os.Create(config.JUnit)
+		if err != nil {
+			return fmt.Errorf("failed to create JUnit report: %w", err)
+		}
+		defer junitFile.Close()
+		reports = append(reports, output.NewJUnitWriter(junitFile))
+	}
+	if config.Textfile != "" {
+		reports = append(reports, output.NewTextfileWriter(config.Textfile))
+	}
+
+	// Структурированный вывод - это данные, поэтому quiet влияет только на текст
+	textOutput := config.Output == output.FormatText
+	showResults := !textOutput || !config.Quiet
+
+	// Итоговая статистика копится по мере поступления результатов,
+	// сами результаты не сохраняются
+	var summary output.Summary
+	startTime := time.Now()
+
+	// Показываем начальное сообщение; со своим форматом строк вывод
+	// полностью определяет шаблон
+	if textOutput && !config.Quiet && config.Format == "" {
+		var rate string
+		if config.Rate > 0 {
+			rate = fmt.Sprintf(", rate: %g/s", config.Rate)
+		}
+		if config.Mode == ModeCrawl {
+			fmt.Printf("Crawling with %d workers (depth: %d, timeout: %v%s)...\n",
+				config.Workers, config.MaxDepth, config.Timeout, rate)
+		} else if source.total > 0 {
+			fmt.Printf("Checking %d URLs with %d workers (timeout: %v%s)...\n",
+				source.total, config.Workers, config.Timeout, rate)
+		} else {
+			fmt.Printf("Checking URLs with %d workers (timeout: %v%s)...\n",
+				config.Workers, config.Timeout, rate)
+		}
+	}
+
+	// Выполняем проверку с callback'ом
+	err = runChecks(ctx, config, source, expect.NewChecker(httpChecker),
+		func(current, total int, result *types.Result) {
+			if showResults {
+				outputWriter.WriteProgress(current, total, *result)
+			}
+			for _, report := range reports {
+				report.WriteProgress(current, total, *result)
+			}
+			if webhook != nil {
+				webhook.Notify(ctx, *result)
+			}
+			if base != nil {
+				summary.Changes = append(summary.Changes, base.Compare(*result)...)
+			}
+			summary.Add(*result)
+		})
+
+	// Обрабатываем ошибки выполнения; остановка по -fail-fast
+	// завершает проверку с частичными итогами
+	var stopErr *worker.StopError
+	if errors.As(err, &stopErr) {
+		summary.Stopped = true
+		summary.Skipped = skippedTargets(config, source.total, summary.Total, stopErr)
+	} else if err != nil {
 		if err == context.Canceled {
 			fmt.Fprintf(os.Stderr, "Operation cancelled by user\n")
-			os.Exit(130) // Стандартный exit code для SIGINT
+			os.Exit(ExitInterrupted)
 		}
 		return fmt.Errorf("execution failed: %w", err)
 	}
 
-	// Выводим итоговую статистику
-	if !config.Quiet {
-		duration := time.Since(startTime)
-		summary := calculateSummary(allResults, duration)
+	if err := source.Err(); err != nil {
+		return fmt.Errorf("failed to get URLs: %w", err)
+	}
+
+	if summary.Total == 0 {
+		return fmt.Errorf("no URLs found to check")
+	}
 
+	// Выводим итоговую статистику
+	summary.Duration = time.Since(startTime)
+	if base != nil {
+		summary.Baseline = base.Path
+		// Пропущенные после остановки цели не считаются отсутствующими
+		if !summary.Stopped {
+			summary.Changes = append(summary.Changes, base.Missing()...)
+		}
+	}
+	if showResults {
 		outputWriter.WriteSummary(summary)
 	}
+	for _, report := range reports {
+		report.WriteSummary(summary)
+	}
+
+	if err := writeErr(append(reports, outputWriter)); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	// Ошибка уведомления не меняет итог проверки, поэтому только выводится
+	if webhook != nil {
+		if err := webhook.Wait(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
 
 	// Определяем exit code
-	exitCode := calculateExitCode(allResults)
+	exitCode, reason := config.ExitPolicy().Evaluate(summary)
+	if reason != "" {
+		fmt.Fprintf(os.Stderr, "Failing: %s\n", reason)
+	}
 
-	if exitCode != 0 {
+	if exitCode != ExitOK {
 		os.Exit(exitCode)
 	}
 
 	return nil
 }
 
-func calculateSummary(results []types.Result, duration time.Duration) output.Summary {
-	total := len(results)
-	success := 0
+// skippedTargets возвращает число целей, проверка которых не начиналась
+// до остановки по -fail-fast. Прерванные проверки начались, поэтому не
+// считаются. В режиме crawl total - это только начальные страницы, а число
+// найденных ссылок неизвестно, поэтому пропущенные не считаются.
+func skippedTargets(config *Config, total, checked int, stopErr *worker.StopError) int {
+	if config.Mode == ModeCrawl || total == 0 {
+		return 0
+	}
+	return max(0, total-checked-stopErr.Interrupted)
+}
+
+// executeWatch периодически проверяет цели до сигнала завершения
+// и выводит только смену их состояния
+func executeWatch(ctx context.Context, config *Config, source *targetSource) error {
+	httpChecker, err := newHTTPChecker(config)
+	if err != nil {
+		return err
+	}
+
+	// Цели проверяются многократно, поэтому источник читается целиком
+	targets := slices.Collect(source.targets)
+	if err := source.Err(); err != nil {
+		return fmt.Errorf("failed to get URLs: %w", err)
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no URLs found to check")
+	}
+
+	out, closeOutput, err := openOutput(config)
+	if err != nil {
+		return err
+	}
+	defer closeOutput()
+
+	// Все writer'ы вывода в консоль умеют выводить смену состояния
+	writer := newResultWriter(config, out)
+
+	webhook, err := newWebhook(config)
+	if err != nil {
+		return err
+	}
+
+	if config.Output == output.FormatText && !config.Quiet {
+		fmt.Printf("Watching %d URLs every %v with %d workers, press Ctrl+C to stop...\n",
+			len(targets), config.Interval, config.Workers)
+	}
+
+	monitor := &watch.Monitor{
+		Checker:      expect.NewChecker(httpChecker),
+		Worker:       newWorker(config),
+		Interval:     config.Interval,
+		OnTransition: writer.(output.TransitionWriter).WriteTransition,
+	}
+	// В режиме watch уведомление отправляется только при падении цели
+	if webhook != nil {
+		webhook.OnError = func(err error) {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		monitor.OnTransition = func(t watch.Transition) {
+			writer.(output.TransitionWriter).WriteTransition(t)
+			if t.To == watch.StateDown {
+				webhook.Notify(ctx, t.Result)
+			}
+		}
+	}
+	if err := monitor.Run(ctx, targets); err != nil {
+		return fmt.Errorf("execution failed: %w", err)
+	}
+	if webhook != nil {
+		if err := webhook.Wait(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if err := writeErr([]output.ResultWriter{writer}); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+// executeExporter обслуживает пробы Prometheus до сигнала завершения
+func executeExporter(ctx context.Context, config *Config) error {
+	httpChecker, err := newHTTPChecker(config)
+	if err != nil {
+		return err
+	}
+
+	template, err := targetTemplate(config)
+	if err != nil {
+		return err
+	}
 
-	for _, result := range results {
-		// Считаем успешными только 2xx статус коды без ошибок
-		if result.Error == nil && result.StatusCode >= 200 && result.StatusCode < 300 {
-			success++
+	var targets []types.Target
+	if config.file != nil {
+		if targets, err = fileTargets(config); err != nil {
+			return err
 		}
 	}
 
-	return output.Summary{
-		Total:    total,
-		Success:  success,
-		Failed:   total - success,
-		Duration: duration,
+	e := &exporter.Exporter{
+		Checker:  expect.NewChecker(httpChecker),
+		Template: template,
+		Targets:  targets,
+		Version:  AppVersion,
 	}
+
+	fmt.Fprintf(os.Stderr, "Serving /probe and /metrics on %s\n", config.Listen)
+	if err := e.ListenAndServe(ctx, config.Listen); err != nil {
+		return fmt.Errorf("exporter failed: %w", err)
+	}
+
+	return nil
 }
 
-// calculateExitCode определяет код выхода программы
-func calculateExitCode(results []types.Result) int {
-	for _, result := range results {
-		if result.Error != nil || result.StatusCode < 200 || result.StatusCode >= 300 {
-			return 1 // Есть неудачные проверки
+// openOutput открывает файл из -output-file или возвращает stdout;
+// close закрывает только файл
+func openOutput(config *Config) (out io.Writer, close func() error, err error) {
+	if config.OutputFile == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(config.OutputFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return f, f.Close, nil
+}
+
+// newResultWriter создает writer для выбранного формата вывода;
+// структурированный вывод пишется в out, текст - всегда в stdout
+func newResultWriter(config *Config, out io.Writer) output.ResultWriter {
+	// Колонки проверены в Validate
+	columns, _ := output.ParseColumns(config.Columns)
+
+	switch config.Output {
+	case output.FormatJSON:
+		return output.NewJSONWriter(out, false)
+	case output.FormatJSONL:
+		return output.NewJSONWriter(out, true)
+	case output.FormatCSV:
+		return output.NewCSVWriter(out, ',', columns)
+	case output.FormatTSV:
+		return output.NewCSVWriter(out, '\t', columns)
+	default:
+		textConfig := output.Config{
+			ColorOutput: config.Color && !config.Quiet,
+			Verbose:     config.Verbose,
+		}
+		if config.Format != "" || config.SummaryFormat != "" {
+			// Шаблоны проверены в Validate
+			w, _ := output.NewTemplateWriter(os.Stdout, textConfig, config.Format, config.SummaryFormat)
+			return w
 		}
+		return output.NewWriter(textConfig)
 	}
-	return 0 // Все проверки успешны
+}
+
+// writeErr возвращает первую ошибку записи среди writer'ов, которые ее отслеживают
+func writeErr(writers []output.ResultWriter) error {
+	for _, w := range writers {
+		if ew, ok := w.(interface{ Err() error }); ok && ew.Err() != nil {
+			return ew.Err()
+		}
+	}
+	return nil
 }
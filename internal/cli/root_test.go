@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nashabanov/urlcheck/internal/expect"
+	"github.com/nashabanov/urlcheck/internal/output"
+	"github.com/nashabanov/urlcheck/internal/types"
+	"github.com/nashabanov/urlcheck/internal/worker"
+)
+
+func TestSkippedTargets(t *testing.T) {
+	check := DefaultConfig()
+	crawl := DefaultConfig()
+	crawl.Mode = ModeCrawl
+
+	testCases := []struct {
+		name        string
+		config      *Config
+		total       int
+		checked     int
+		interrupted int
+		expected    int
+	}{
+		{"not started", check, 10, 3, 0, 7},
+		{"interrupted are not skipped", check, 10, 3, 2, 5},
+		{"unknown total", check, 0, 3, 0, 0},
+		{"never negative", check, 3, 3, 2, 0},
+		{"crawl", crawl, 1, 3, 0, 0},
+	}
+
+	for _, tc := range testCases {
+		got := skippedTargets(tc.config, tc.total, tc.checked, &worker.StopError{Interrupted: tc.interrupted})
+		if got != tc.expected {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestRunChecks_CrawlFailFast(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/missing">x</a><a href="/a">a</a><a href="/b">b</a>`)
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.Mode = ModeCrawl
+	config.URLs = server.URL + "/index.html"
+	config.FailFast = true
+	config.Workers = 1
+
+	source, err := openTargets(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+
+	httpChecker, err := newHTTPChecker(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var summary output.Summary
+	err = runChecks(context.Background(), config, source, expect.NewChecker(httpChecker),
+		func(_, _ int, result *types.Result) { summary.Add(*result) })
+
+	var stopErr *worker.StopError
+	if !errors.As(err, &stopErr) {
+		t.Fatalf("Expected fail-fast stop, got %v", err)
+	}
+	// Начальная страница одна, а проверено больше: число ссылок заранее неизвестно
+	if summary.Total <= source.total {
+		t.Fatalf("Expected links to be checked after the seed, got %d results", summary.Total)
+	}
+	if skipped := skippedTargets(config, source.total, summary.Total, stopErr); skipped != 0 {
+		t.Errorf("Expected skipped 0 in crawl mode, got %d", skipped)
+	}
+}
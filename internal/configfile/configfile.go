@@ -0,0 +1,351 @@
+package configfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/expect"
+	"github.com/nashabanov/urlcheck/internal/output"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// File - содержимое файла конфигурации (YAML или JSON)
+type File struct {
+	Defaults Defaults `yaml:"defaults"`
+	Targets  []Target `yaml:"targets"`
+
+	path string
+	root *yaml.Node
+}
+
+// Defaults - глобальные настройки. nil означает, что параметр в файле
+// не задан и используется значение флага.
+type Defaults struct {
+	Workers *int           `yaml:"workers"`
+	Timeout *time.Duration `yaml:"timeout"`
+	MaxURLs *int           `yaml:"max_urls"`
+
+	MaxPerHost *int           `yaml:"max_per_host"`
+	HostDelay  *time.Duration `yaml:"host_delay"`
+	PerIP      *bool          `yaml:"per_ip"`
+
+	Rate  *float64 `yaml:"rate"`
+	Burst *int     `yaml:"burst"`
+
+	Retries       *int           `yaml:"retries"`
+	RetryDelay    *time.Duration `yaml:"retry_delay"`
+	RetryMaxDelay *time.Duration `yaml:"retry_max_delay"`
+	RetryJitter   *float64       `yaml:"retry_jitter"`
+	RetryOn       *string        `yaml:"retry_on"`
+
+	Redirects    *string `yaml:"redirects"`
+	MaxRedirects *int    `yaml:"max_redirects"`
+
+	CertWarnDays *int `yaml:"cert_warn_days"`
+
+	Interval *time.Duration `yaml:"interval"`
+	Listen   *string        `yaml:"listen"`
+
+	MaxDepth   *int  `yaml:"max_depth"`
+	SameDomain *bool `yaml:"same_domain"`
+
+	Baseline          *string  `yaml:"baseline"`
+	LatencyRegression *float64 `yaml:"latency_regression"`
+
+	MaxFailures    *int     `yaml:"max_failures"`
+	MaxFailureRate *float64 `yaml:"max_failure_rate"`
+	CriticalTag    *string  `yaml:"critical_tag"`
+	FailFast       *bool    `yaml:"fail_fast"`
+
+	Webhook         *string        `yaml:"webhook"`
+	WebhookTemplate *string        `yaml:"webhook_template"`
+	WebhookRetries  *int           `yaml:"webhook_retries"`
+	WebhookMute     *time.Duration `yaml:"webhook_mute"`
+
+	Output        *string `yaml:"output"`
+	OutputFile    *string `yaml:"output_file"`
+	Columns       *string `yaml:"columns"`
+	Format        *string `yaml:"format"`
+	SummaryFormat *string `yaml:"summary_format"`
+	JUnit         *string `yaml:"junit"`
+	Textfile      *string `yaml:"textfile"`
+	Color         *bool   `yaml:"color"`
+	Quiet         *bool   `yaml:"quiet"`
+	Verbose       *bool   `yaml:"verbose"`
+
+	Request checker.RequestSpec `yaml:",inline"`
+	Expect  expect.Spec         `yaml:"expect"`
+}
+
+// Target - цель с собственными настройками поверх Defaults
+type Target struct {
+	URL     string              `yaml:"url"`
+	Request checker.RequestSpec `yaml:",inline"`
+	Expect  expect.Spec         `yaml:"expect"`
+
+	// Interval - собственный период проверки в режиме watch
+	Interval time.Duration `yaml:"interval"`
+
+	Tags []string `yaml:"tags"`
+}
+
+// Error - ошибка в файле конфигурации с указанием строки
+type Error struct {
+	Path  string
+	Line  int
+	Field string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: %s: %v", e.Path, e.Line, e.Field, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Load читает и проверяет файл конфигурации
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return Parse(path, data)
+}
+
+// Parse разбирает и проверяет содержимое файла конфигурации; path
+// используется только в сообщениях об ошибках
+func Parse(path string, data []byte) (*File, error) {
+	f := &File{path: path, root: &yaml.Node{}}
+
+	if err := yaml.Unmarshal(data, f.root); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// TargetList строит цели, применяя настройки по умолчанию, собственные
+// настройки цели и переопределения из флагов (в порядке возрастания приоритета)
+func (f *File) TargetList(flagRequest checker.RequestSpec, flagExpect expect.Spec) ([]types.Target, error) {
+	targets := make([]types.Target, len(f.Targets))
+
+	for i, t := range f.Targets {
+		target := types.Target{URL: t.URL, Interval: t.Interval, Tags: t.Tags}
+
+		if err := f.Defaults.Request.Merge(t.Request).Merge(flagRequest).Apply(&target); err != nil {
+			return nil, err
+		}
+
+		e, err := f.Defaults.Expect.Merge(t.Expect).Merge(flagExpect).Compile()
+		if err != nil {
+			return nil, err
+		}
+		target.Expect = e
+
+		targets[i] = target
+	}
+
+	return targets, nil
+}
+
+func (f *File) validate() error {
+	d := f.Defaults
+
+	if d.Workers != nil && (*d.Workers <= 0 || *d.Workers > 1000) {
+		return f.errorAt(fmt.Errorf("must be between 1 and 1000"), "defaults", "workers")
+	}
+	if d.Timeout != nil && *d.Timeout <= 0 {
+		return f.errorAt(fmt.Errorf("must be positive"), "defaults", "timeout")
+	}
+	if d.MaxURLs != nil && *d.MaxURLs < 0 {
+		return f.errorAt(fmt.Errorf("must not be negative"), "defaults", "max_urls")
+	}
+	if d.MaxPerHost != nil && *d.MaxPerHost < 0 {
+		return f.errorAt(fmt.Errorf("must not be negative"), "defaults", "max_per_host")
+	}
+	if d.HostDelay != nil && *d.HostDelay < 0 {
+		return f.errorAt(fmt.Errorf("must not be negative"), "defaults", "host_delay")
+	}
+	if d.Rate != nil && *d.Rate < 0 {
+		return f.errorAt(fmt.Errorf("must not be negative"), "defaults", "rate")
+	}
+	if d.Burst != nil && *d.Burst < 1 {
+		return f.errorAt(fmt.Errorf("must be at least 1"), "defaults", "burst")
+	}
+	if d.Retries != nil && *d.Retries < 0 {
+		return f.errorAt(fmt.Errorf("must not be negative"), "defaults", "retries")
+	}
+	if d.RetryJitter != nil && (*d.RetryJitter < 0 || *d.RetryJitter > 1) {
+		return f.errorAt(fmt.Errorf("must be between 0 and 1"), "defaults", "retry_jitter")
+	}
+	if d.Redirects != nil && !checker.ValidRedirectMode(*d.Redirects) {
+		return f.errorAt(fmt.Errorf("unknown redirect mode %q", *d.Redirects), "defaults", "redirects")
+	}
+	if d.MaxRedirects != nil && *d.MaxRedirects < 1 {
+		return f.errorAt(fmt.Errorf("must be at least 1"), "defaults", "max_redirects")
+	}
+	if d.CertWarnDays != nil && *d.CertWarnDays < 0 {
+		return f.errorAt(fmt.Errorf("must not be negative"), "defaults", "cert_warn_days")
+	}
+	if d.Interval != nil && *d.Interval <= 0 {
+		return f.errorAt(fmt.Errorf("must be positive"), "defaults", "interval")
+	}
+	if d.MaxDepth != nil && *d.MaxDepth < 0 {
+		return f.errorAt(fmt.Errorf("must not be negative"), "defaults", "max_depth")
+	}
+	if d.LatencyRegression != nil && *d.LatencyRegression < 0 {
+		return f.errorAt(fmt.Errorf("must not be negative"), "defaults", "latency_regression")
+	}
+	if d.MaxFailures != nil && *d.MaxFailures < -1 {
+		return f.errorAt(fmt.Errorf("must not be negative (-1 disables it)"), "defaults", "max_failures")
+	}
+	if d.MaxFailureRate != nil && (*d.MaxFailureRate < -1 || *d.MaxFailureRate > 100) {
+		return f.errorAt(fmt.Errorf("must be between 0 and 100 (-1 disables it)"), "defaults", "max_failure_rate")
+	}
+	if d.WebhookRetries != nil && *d.WebhookRetries < 0 {
+		return f.errorAt(fmt.Errorf("must not be negative"), "defaults", "webhook_retries")
+	}
+	if d.WebhookMute != nil && *d.WebhookMute < 0 {
+		return f.errorAt(fmt.Errorf("must not be negative"), "defaults", "webhook_mute")
+	}
+	if d.Columns != nil {
+		if _, err := output.ParseColumns(*d.Columns); err != nil {
+			return f.errorAt(err, "defaults", "columns")
+		}
+	}
+	if d.Output != nil {
+		switch *d.Output {
+		case output.FormatText, output.FormatJSON, output.FormatJSONL, output.FormatCSV, output.FormatTSV:
+		default:
+			return f.errorAt(fmt.Errorf("unknown output format %q", *d.Output), "defaults", "output")
+		}
+	}
+
+	if err := d.Request.Apply(&types.Target{}); err != nil {
+		return f.specError(err, "defaults")
+	}
+
+	if _, err := d.Expect.Compile(); err != nil {
+		return f.specError(err, "defaults", "expect")
+	}
+
+	for i, t := range f.Targets {
+		index := strconv.Itoa(i)
+
+		if t.URL == "" {
+			return f.errorAt(fmt.Errorf("is required"), "targets", index, "url")
+		}
+		if u, err := url.Parse(t.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return f.errorAt(fmt.Errorf("invalid http(s) URL %q", t.URL), "targets", index, "url")
+		}
+
+		if t.Interval < 0 {
+			return f.errorAt(fmt.Errorf("must not be negative"), "targets", index, "interval")
+		}
+
+		for j, tag := range t.Tags {
+			if strings.TrimSpace(tag) == "" {
+				return f.errorAt(fmt.Errorf("must not be empty"), "targets", index, "tags", strconv.Itoa(j))
+			}
+		}
+
+		if err := d.Request.Merge(t.Request).Apply(&types.Target{}); err != nil {
+			return f.specError(err, "targets", index)
+		}
+
+		if _, err := d.Expect.Merge(t.Expect).Compile(); err != nil {
+			return f.specError(err, "targets", index, "expect")
+		}
+	}
+
+	return nil
+}
+
+// specError привязывает ошибку ожидания к строке поля, в котором она возникла
+func (f *File) specError(err error, path ...string) error {
+	var fieldErr *types.FieldError
+	if errors.As(err, &fieldErr) {
+		return f.errorAt(fieldErr.Err, append(path, fieldErr.Field)...)
+	}
+	return f.errorAt(err, path...)
+}
+
+// errorAt создает ошибку для поля по пути path. Если поле отсутствует
+// в файле (значение унаследовано), указывается строка ближайшего предка.
+func (f *File) errorAt(err error, path ...string) error {
+	node := f.root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, key := range path {
+		child := childNode(node, key)
+		if child == nil {
+			break
+		}
+		node = child
+	}
+
+	return &Error{
+		Path:  f.path,
+		Line:  node.Line,
+		Field: fieldPath(path),
+		Err:   err,
+	}
+}
+
+func childNode(node *yaml.Node, key string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// fieldPath форматирует путь как targets[3].expect.status
+func fieldPath(path []string) string {
+	var s string
+	for _, key := range path {
+		if _, err := strconv.Atoi(key); err == nil {
+			s += "[" + key + "]"
+		} else if s == "" {
+			s = key
+		} else {
+			s += "." + key
+		}
+	}
+	return s
+}
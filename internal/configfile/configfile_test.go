@@ -0,0 +1,213 @@
+package configfile
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/expect"
+)
+
+const validConfig = `
+defaults:
+  workers: 20
+  timeout: 3s
+  retries: 2
+  output: jsonl
+  headers:
+    X-Api-Key: secret
+  expect:
+    status: 2xx
+    max_latency: 1s
+targets:
+  - url: https://example.com
+  - url: https://example.com/login
+    interval: 10s
+    method: post
+    body: '{"user": "probe"}'
+    expect:
+      status: "200,401"
+      body_contains: ["Sign in"]
+      headers: ["Content-Type: html"]
+`
+
+func TestParse_Valid(t *testing.T) {
+	f, err := Parse("urlcheck.yaml", []byte(validConfig))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if f.Defaults.Workers == nil || *f.Defaults.Workers != 20 {
+		t.Errorf("Expected workers 20, got %v", f.Defaults.Workers)
+	}
+	if f.Defaults.Timeout == nil || *f.Defaults.Timeout != 3*time.Second {
+		t.Errorf("Expected timeout 3s, got %v", f.Defaults.Timeout)
+	}
+	if f.Defaults.Quiet != nil {
+		t.Errorf("Expected quiet to be unset, got %v", *f.Defaults.Quiet)
+	}
+
+	targets, err := f.TargetList(checker.RequestSpec{}, expect.Spec{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("Expected 2 targets, got %d", len(targets))
+	}
+
+	first := targets[0].Expect
+	if len(first.Statuses) != 1 || first.Statuses[0].Min != 200 || first.MaxLatency != time.Second {
+		t.Errorf("Expected defaults for first target, got %+v", first)
+	}
+
+	second := targets[1].Expect
+	if len(second.Statuses) != 2 || len(second.BodyContains) != 1 || len(second.Headers) != 1 {
+		t.Errorf("Expected overrides for second target, got %+v", second)
+	}
+	if second.MaxLatency != time.Second {
+		t.Errorf("Expected inherited max latency, got %v", second.MaxLatency)
+	}
+
+	if targets[0].Interval != 0 || targets[1].Interval != 10*time.Second {
+		t.Errorf("Expected intervals 0 and 10s, got %v and %v", targets[0].Interval, targets[1].Interval)
+	}
+	if targets[0].Method != "" || targets[1].Method != "POST" {
+		t.Errorf("Expected methods \"\" and POST, got %q and %q", targets[0].Method, targets[1].Method)
+	}
+	if string(targets[1].Body) != `{"user": "probe"}` {
+		t.Errorf("Expected target body, got %q", targets[1].Body)
+	}
+	for _, target := range targets {
+		if target.Headers.Get("X-Api-Key") != "secret" {
+			t.Errorf("Expected default header for %s, got %v", target.URL, target.Headers)
+		}
+	}
+}
+
+func TestParse_JSON(t *testing.T) {
+	data := `{"defaults": {"workers": 3}, "targets": [{"url": "http://example.com"}]}`
+
+	f, err := Parse("urlcheck.json", []byte(data))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *f.Defaults.Workers != 3 || len(f.Targets) != 1 {
+		t.Errorf("Unexpected config: %+v", f)
+	}
+}
+
+func TestTargetList_FlagsWin(t *testing.T) {
+	f, err := Parse("urlcheck.yaml", []byte(validConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := checker.RequestSpec{Method: "HEAD", Headers: map[string]string{"x-api-key": "other"}}
+	targets, err := f.TargetList(request, expect.Spec{Status: "3xx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range targets {
+		if len(target.Expect.Statuses) != 1 || target.Expect.Statuses[0].Min != 300 {
+			t.Errorf("Expected flag status to win for %s, got %+v", target.URL, target.Expect.Statuses)
+		}
+		if target.Method != "HEAD" || target.Headers.Get("X-Api-Key") != "other" {
+			t.Errorf("Expected flag request to win for %s, got %s %v", target.URL, target.Method, target.Headers)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "invalid status in target",
+			data:     "targets:\n  - url: http://a.com\n  - url: http://b.com\n    expect:\n      status: abc\n",
+			expected: "urlcheck.yaml:5: targets[1].expect.status: invalid status code",
+		},
+		{
+			name:     "missing url",
+			data:     "targets:\n  - url: http://a.com\n  - expect:\n      status: 200\n",
+			expected: "urlcheck.yaml:3: targets[1].url: is required",
+		},
+		{
+			name:     "invalid url",
+			data:     "targets:\n  - url: ftp://a.com\n",
+			expected: "urlcheck.yaml:2: targets[0].url: invalid http(s) URL",
+		},
+		{
+			name:     "invalid workers",
+			data:     "defaults:\n  timeout: 1s\n  workers: 0\n",
+			expected: "urlcheck.yaml:3: defaults.workers: must be between 1 and 1000",
+		},
+		{
+			name:     "invalid regexp in defaults",
+			data:     "defaults:\n  expect:\n    body_matches: ['(']\n",
+			expected: "urlcheck.yaml:3: defaults.expect.body_matches:",
+		},
+		{
+			name:     "invalid method in target",
+			data:     "targets:\n  - url: http://a.com\n    method: 'GE T'\n",
+			expected: "urlcheck.yaml:3: targets[0].method: invalid method",
+		},
+		{
+			name:     "body and body_file in defaults",
+			data:     "defaults:\n  body: x\n  body_file: y.json\n",
+			expected: "urlcheck.yaml:3: defaults.body_file: body and body_file are mutually exclusive",
+		},
+		{
+			name:     "unknown redirect mode",
+			data:     "defaults:\n  redirects: never\n",
+			expected: "urlcheck.yaml:2: defaults.redirects: unknown redirect mode",
+		},
+		{
+			name:     "negative max_redirects expectation",
+			data:     "targets:\n  - url: http://a.com\n    expect:\n      max_redirects: -1\n",
+			expected: "urlcheck.yaml:4: targets[0].expect.max_redirects: must not be negative",
+		},
+		{
+			name:     "unknown field",
+			data:     "targets:\n  - url: http://a.com\n    tiemout: 1s\n",
+			expected: "line 3: field tiemout not found",
+		},
+		{
+			name:     "wrong type",
+			data:     "defaults:\n  workers: many\n",
+			expected: "line 2:",
+		},
+		{
+			name:     "syntax error",
+			data:     "targets: [\n",
+			expected: "urlcheck.yaml: yaml:",
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := Parse("urlcheck.yaml", []byte(tc.data))
+		if err == nil {
+			t.Errorf("%s: expected error, got nil", tc.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%s: expected error containing %q, got %q", tc.name, tc.expected, err)
+		}
+	}
+}
+
+func TestParse_ErrorType(t *testing.T) {
+	_, err := Parse("urlcheck.yaml", []byte("targets:\n  - url: ''\n"))
+
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Expected *Error, got %T", err)
+	}
+	if cfgErr.Line != 2 || cfgErr.Field != "targets[0].url" {
+		t.Errorf("Unexpected error location: line %d, field %s", cfgErr.Line, cfgErr.Field)
+	}
+}
@@ -0,0 +1,165 @@
+package crawl
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+	"github.com/nashabanov/urlcheck/internal/worker"
+)
+
+// DefaultMaxDepth - по умолчанию проверяются начальные страницы
+// и ссылки на них, но сами найденные страницы не разбираются
+const DefaultMaxDepth = 1
+
+// Crawler проверяет начальные страницы и ссылки, найденные на них.
+// Страницы обходятся по уровням: ссылки со страниц уровня N проверяются
+// пулом воркеров как уровень N+1.
+type Crawler struct {
+	Checker checker.Checker
+	Worker  *worker.Worker
+
+	// Template - параметры запроса и ожидания для найденных ссылок
+	Template types.Target
+
+	// MaxDepth - наибольшее число переходов по ссылкам от начальных страниц
+	MaxDepth int
+	// SameDomain разбирает только страницы на хостах начальных страниц;
+	// ссылки на другие хосты проверяются, но не обходятся
+	SameDomain bool
+	// MaxURLs ограничивает число проверяемых URL; 0 - без ограничения
+	MaxURLs int
+
+	seen    map[string]bool
+	hosts   map[string]bool
+	dropped int
+}
+
+// Run проверяет seeds и найденные ссылки. У результатов ссылок заполнен
+// Referrer - первая страница, на которой ссылка найдена. callback
+// вызывается последовательно; общее число URL заранее неизвестно,
+// поэтому total всегда 0.
+func (c *Crawler) Run(
+	ctx context.Context,
+	seeds []types.Target,
+	callback func(current, total int, result *types.Result),
+) error {
+	c.seen = map[string]bool{}
+	c.hosts = map[string]bool{}
+	c.dropped = 0
+
+	var level []types.Target
+	for _, t := range seeds {
+		// Некорректный URL все равно проверяется, чтобы попасть в отчет
+		if u, err := url.Parse(t.URL); err == nil {
+			if !c.add(u) {
+				continue
+			}
+			c.hosts[strings.ToLower(u.Hostname())] = true
+		}
+		if c.MaxDepth > 0 {
+			t = page(t)
+		}
+		level = append(level, t)
+	}
+
+	checked := 0
+	for depth := 0; len(level) > 0; depth++ {
+		var next []types.Target
+
+		err := c.Worker.Run(ctx, c.Checker, level, func(_, _ int, result *types.Result) {
+			// Начальная страница может перенаправлять на другой хост
+			// (http -> https, без www -> www); ее ссылки тоже в пределах обхода
+			if depth == 0 && result.FinalURL != "" {
+				if u, err := url.Parse(result.FinalURL); err == nil {
+					c.hosts[strings.ToLower(u.Hostname())] = true
+				}
+			}
+			if result.Body != nil && depth < c.MaxDepth {
+				next = append(next, c.links(result, depth+1)...)
+			}
+			result.Body = nil
+
+			checked++
+			callback(checked, 0, result)
+		})
+		if err != nil {
+			return err
+		}
+
+		level = next
+	}
+
+	return nil
+}
+
+// Dropped возвращает число найденных ссылок, не проверенных из-за MaxURLs
+func (c *Crawler) Dropped() int {
+	return c.dropped
+}
+
+// links возвращает цели для новых ссылок со страницы result
+func (c *Crawler) links(result *types.Result, depth int) []types.Target {
+	if !result.IsSuccess() || !IsHTML(result.Header) {
+		return nil
+	}
+
+	// Относительные ссылки разрешаются от адреса после редиректов
+	pageURL := result.URL
+	if result.FinalURL != "" {
+		pageURL = result.FinalURL
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	var targets []types.Target
+	for _, link := range ExtractLinks(base, result.Body) {
+		u, err := url.Parse(link)
+		if err != nil || !c.add(u) {
+			continue
+		}
+
+		target := c.Template
+		target.URL = link
+		target.Referrer = result.URL
+		if depth < c.MaxDepth && c.inScope(u) {
+			target = page(target)
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// add отмечает URL как найденный и сообщает, нужно ли его проверять
+func (c *Crawler) add(u *url.URL) bool {
+	key := Normalize(u)
+	if c.seen[key] {
+		return false
+	}
+	if c.MaxURLs > 0 && len(c.seen) >= c.MaxURLs {
+		c.dropped++
+		return false
+	}
+	c.seen[key] = true
+	return true
+}
+
+// inScope сообщает, можно ли разбирать страницу по адресу u
+func (c *Crawler) inScope(u *url.URL) bool {
+	return !c.SameDomain || c.hosts[strings.ToLower(u.Hostname())]
+}
+
+// page превращает цель в страницу, ссылки которой будут разобраны:
+// тело ответа сохраняется, а HEAD заменяется на GET
+func page(t types.Target) types.Target {
+	t.KeepBody = true
+	if t.Method == http.MethodHead {
+		t.Method = http.MethodGet
+	}
+	return t
+}
@@ -0,0 +1,163 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/expect"
+	"github.com/nashabanov/urlcheck/internal/types"
+	"github.com/nashabanov/urlcheck/internal/worker"
+)
+
+// newSite запускает сайт из страниц path -> html; остальные пути
+// отвечают 404. Адрес сайта подставляется вместо {{site}}.
+func newSite(t *testing.T, pages map[string]string) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, strings.ReplaceAll(page, "{{site}}", srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func runCrawler(t *testing.T, c *Crawler, seeds ...string) map[string]types.Result {
+	c.Checker = expect.NewChecker(checker.NewHTTPChecker())
+	c.Worker = &worker.Worker{MaxWorkers: 4}
+
+	results := map[string]types.Result{}
+	err := c.Run(context.Background(), types.NewTargets(seeds), func(_, total int, result *types.Result) {
+		if total != 0 {
+			t.Errorf("Expected unknown total, got %d", total)
+		}
+		if result.Body != nil {
+			t.Errorf("Expected body of %s to be released", result.URL)
+		}
+		if _, ok := results[result.URL]; ok {
+			t.Errorf("URL %s checked twice", result.URL)
+		}
+		results[result.URL] = *result
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return results
+}
+
+func TestCrawler_Run(t *testing.T) {
+	// Хосты сайтов отличаются, порты при этом не учитываются
+	externalSite := newSite(t, map[string]string{
+		"/": `<a href="/deep">deep</a>`,
+	})
+	external := strings.Replace(externalSite.URL, "127.0.0.1", "localhost", 1)
+	site := newSite(t, map[string]string{
+		"/":        `<a href="/docs">Docs</a> <a href="/missing">Missing</a> <a href="` + external + `/">Ext</a>`,
+		"/docs":    `<a href="/">Home</a> <img src="/img.png"> <a href="` + external + `/broken">Ext</a>`,
+		"/img.png": "",
+	})
+
+	results := runCrawler(t, &Crawler{MaxDepth: 2, SameDomain: true}, site.URL+"/")
+
+	expected := map[string]int{
+		site.URL + "/":        200,
+		site.URL + "/docs":    200,
+		site.URL + "/missing": 404,
+		external + "/":        200,
+		site.URL + "/img.png": 200,
+		external + "/broken":  404,
+	}
+	if len(results) != len(expected) {
+		t.Errorf("Expected %d URLs, got %d: %v", len(expected), len(results), results)
+	}
+	for u, status := range expected {
+		if r, ok := results[u]; !ok || r.StatusCode != status {
+			t.Errorf("%s: expected status %d, got %+v", u, status, r)
+		}
+	}
+
+	// Страницы другого хоста проверяются, но не обходятся
+	if _, ok := results[external+"/deep"]; ok {
+		t.Error("Expected external page not to be crawled")
+	}
+
+	if r := results[site.URL+"/missing"]; r.Referrer != site.URL+"/" {
+		t.Errorf("Expected /missing to be referred by the seed, got %q", r.Referrer)
+	}
+	if r := results[external+"/broken"]; r.Referrer != site.URL+"/docs" {
+		t.Errorf("Expected external /broken to be referred by /docs, got %q", r.Referrer)
+	}
+	if r := results[site.URL+"/"]; r.Referrer != "" {
+		t.Errorf("Expected no referrer for the seed, got %q", r.Referrer)
+	}
+}
+
+func TestCrawler_SeedRedirect(t *testing.T) {
+	site := newSite(t, map[string]string{
+		"/":     `<a href="/docs">Docs</a>`,
+		"/docs": `<a href="/deep">Deep</a>`,
+		"/deep": "",
+	})
+	redirector := httptest.NewServer(http.RedirectHandler(site.URL+"/", http.StatusMovedPermanently))
+	t.Cleanup(redirector.Close)
+	// Начальный адрес на другом хосте перенаправляет на сайт
+	seed := strings.Replace(redirector.URL, "127.0.0.1", "localhost", 1) + "/"
+
+	results := runCrawler(t, &Crawler{MaxDepth: 2, SameDomain: true}, seed)
+
+	// Страницы хоста после редиректа обходятся, поэтому найдена и /deep
+	for _, url := range []string{seed, site.URL + "/docs", site.URL + "/deep"} {
+		if results[url].StatusCode != 200 {
+			t.Errorf("Expected %s to be checked with 200, got %+v", url, results[url])
+		}
+	}
+}
+
+func TestCrawler_MaxDepth(t *testing.T) {
+	site := newSite(t, map[string]string{
+		"/":  `<a href="/a">a</a>`,
+		"/a": `<a href="/b">b</a>`,
+		"/b": `<a href="/c">c</a>`,
+	})
+
+	testCases := []struct {
+		depth    int
+		expected int
+	}{
+		{0, 1},
+		{1, 2},
+		{2, 3},
+		{5, 4},
+	}
+
+	for _, tc := range testCases {
+		results := runCrawler(t, &Crawler{MaxDepth: tc.depth, SameDomain: true}, site.URL)
+		if len(results) != tc.expected {
+			t.Errorf("Depth %d: expected %d URLs, got %d", tc.depth, tc.expected, len(results))
+		}
+	}
+}
+
+func TestCrawler_MaxURLs(t *testing.T) {
+	site := newSite(t, map[string]string{
+		"/": `<a href="/1">1</a><a href="/2">2</a><a href="/3">3</a><a href="/4">4</a>`,
+	})
+
+	c := &Crawler{MaxDepth: 1, MaxURLs: 3}
+	results := runCrawler(t, c, site.URL)
+	if len(results) != 3 {
+		t.Errorf("Expected 3 URLs, got %d", len(results))
+	}
+	if c.Dropped() != 2 {
+		t.Errorf("Expected 2 dropped links, got %d", c.Dropped())
+	}
+}
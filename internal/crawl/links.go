@@ -0,0 +1,108 @@
+package crawl
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// linkAttrs - атрибуты со ссылками для каждого разбираемого тега
+var linkAttrs = map[string]string{
+	"a":      "href",
+	"img":    "src",
+	"script": "src",
+	"link":   "href",
+}
+
+// skipRel - значения rel у <link>, в которых указан адрес сервера, а не ресурса
+var skipRel = map[string]bool{
+	"preconnect":   true,
+	"dns-prefetch": true,
+}
+
+// IsHTML сообщает, является ли ответ HTML-страницей
+func IsHTML(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && (mediaType == "text/html" || mediaType == "application/xhtml+xml")
+}
+
+// ExtractLinks возвращает ссылки страницы из <a href>, <img src>,
+// <script src> и <link href>. Относительные ссылки разрешаются от page
+// или от <base href>; возвращаются только http и https адреса без
+// фрагментов, каждый один раз, в порядке появления на странице.
+func ExtractLinks(page *url.URL, body []byte) []string {
+	base := page
+	seen := map[string]bool{}
+	var links []string
+
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// Ошибка токенизатора - это конец тела, в том числе обрезанного
+			return links
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := z.Token()
+		if token.Data == "base" {
+			if href := attr(token, "href"); href != "" {
+				if u, err := page.Parse(href); err == nil {
+					base = u
+				}
+			}
+			continue
+		}
+
+		name, ok := linkAttrs[token.Data]
+		if !ok {
+			continue
+		}
+		if token.Data == "link" && skipRel[strings.ToLower(attr(token, "rel"))] {
+			continue
+		}
+
+		ref := strings.TrimSpace(attr(token, name))
+		if ref == "" || strings.HasPrefix(ref, "#") {
+			continue
+		}
+		u, err := base.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+
+		link := Normalize(u)
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+}
+
+// Normalize приводит URL к виду, по которому ссылки сравниваются:
+// без фрагмента, с хостом в нижнем регистре и непустым путем
+func Normalize(u *url.URL) string {
+	n := *u
+	n.Fragment = ""
+	n.RawFragment = ""
+	n.Host = strings.ToLower(n.Host)
+	if n.Path == "" && n.Opaque == "" {
+		n.Path = "/"
+	}
+	return n.String()
+}
+
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
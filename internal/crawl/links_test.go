@@ -0,0 +1,73 @@
+package crawl
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestExtractLinks(t *testing.T) {
+	page, _ := url.Parse("https://Docs.example.com/guide/intro.html")
+
+	body := []byte(`<!DOCTYPE html>
+<html><head>
+  <link rel="stylesheet" href="/css/main.css">
+  <link rel="preconnect" href="https://fonts.example.net">
+  <script src="app.js"></script>
+</head><body>
+  <a href="install.html#linux">Install</a>
+  <a href="install.html">Install again</a>
+  <a href="#top">Top</a>
+  <a href="mailto:docs@example.com">Mail</a>
+  <a href="javascript:void(0)">Menu</a>
+  <a href="HTTPS://GitHub.com">GitHub</a>
+  <a>No href</a>
+  <img src="../img/logo.png"/>
+  <a href="https://other.example.org/page?q=1">Other</a>
+</body></html>`)
+
+	expected := []string{
+		"https://docs.example.com/css/main.css",
+		"https://docs.example.com/guide/app.js",
+		"https://docs.example.com/guide/install.html",
+		"https://github.com/",
+		"https://docs.example.com/img/logo.png",
+		"https://other.example.org/page?q=1",
+	}
+
+	if got := ExtractLinks(page, body); !slices.Equal(got, expected) {
+		t.Errorf("Expected links %v, got %v", expected, got)
+	}
+}
+
+func TestExtractLinks_Base(t *testing.T) {
+	page, _ := url.Parse("https://example.com/a/b.html")
+	body := []byte(`<head><base href="https://cdn.example.com/v2/"></head><a href="page.html">x</a>`)
+
+	got := ExtractLinks(page, body)
+	if len(got) != 1 || got[0] != "https://cdn.example.com/v2/page.html" {
+		t.Errorf("Expected link resolved against <base>, got %v", got)
+	}
+}
+
+func TestIsHTML(t *testing.T) {
+	testCases := []struct {
+		contentType string
+		expected    bool
+	}{
+		{"text/html", true},
+		{"text/html; charset=utf-8", true},
+		{"application/xhtml+xml", true},
+		{"application/json", false},
+		{"image/png", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		header := http.Header{"Content-Type": {tc.contentType}}
+		if got := IsHTML(header); got != tc.expected {
+			t.Errorf("%q: expected %v, got %v", tc.contentType, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package expect
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// Имена ожиданий в результатах проверки
+const (
+	AssertStatus          = "status"
+	AssertLatency         = "latency"
+	AssertBodyContains    = "body_contains"
+	AssertBodyNotContains = "body_not_contains"
+	AssertBodyMatches     = "body_matches"
+	AssertBodyNotMatches  = "body_not_matches"
+	AssertHeader          = "header"
+	AssertRedirects       = "redirects"
+	AssertHTTPS           = "https"
+)
+
+// Checker проверяет ожидания цели после основного Checker'а
+// и освобождает прочитанное тело ответа, если цель не просит его сохранить
+type Checker struct {
+	Next checker.Checker
+}
+
+func NewChecker(next checker.Checker) *Checker {
+	return &Checker{Next: next}
+}
+
+func (c *Checker) Check(ctx context.Context, target types.Target) *types.Result {
+	result := c.Next.Check(ctx, target)
+	Evaluate(target.Expect, result)
+	if !target.KeepBody {
+		result.Body = nil
+	}
+	return result
+}
+
+// Evaluate заполняет result.Assertions. Если запрос завершился ошибкой,
+// ожидания не проверяются.
+func Evaluate(e types.Expectations, result *types.Result) {
+	if result.Error != nil {
+		return
+	}
+
+	assertions := []types.Assertion{checkStatus(e.Statuses, result.StatusCode)}
+
+	if e.MaxLatency > 0 {
+		assertions = append(assertions, types.Assertion{
+			Name:    AssertLatency,
+			Passed:  result.Duration <= e.MaxLatency,
+			Message: fmt.Sprintf("latency %v exceeds %v", result.Duration, e.MaxLatency),
+		})
+	}
+
+	for _, s := range e.BodyContains {
+		assertions = append(assertions, types.Assertion{
+			Name:    AssertBodyContains,
+			Passed:  bytes.Contains(result.Body, []byte(s)),
+			Message: fmt.Sprintf("body does not contain %q", s),
+		})
+	}
+
+	for _, s := range e.BodyNotContains {
+		assertions = append(assertions, types.Assertion{
+			Name:    AssertBodyNotContains,
+			Passed:  !bytes.Contains(result.Body, []byte(s)),
+			Message: fmt.Sprintf("body contains %q", s),
+		})
+	}
+
+	for _, re := range e.BodyMatches {
+		assertions = append(assertions, types.Assertion{
+			Name:    AssertBodyMatches,
+			Passed:  re.Match(result.Body),
+			Message: fmt.Sprintf("body does not match /%s/", re),
+		})
+	}
+
+	for _, re := range e.BodyNotMatches {
+		assertions = append(assertions, types.Assertion{
+			Name:    AssertBodyNotMatches,
+			Passed:  !re.Match(result.Body),
+			Message: fmt.Sprintf("body matches /%s/", re),
+		})
+	}
+
+	for _, h := range e.Headers {
+		assertions = append(assertions, checkHeader(h, result.Header))
+	}
+
+	if e.MaxRedirects > 0 {
+		assertions = append(assertions, types.Assertion{
+			Name:    AssertRedirects,
+			Passed:  len(result.Redirects) <= e.MaxRedirects,
+			Message: fmt.Sprintf("redirect chain of %d exceeds %d", len(result.Redirects), e.MaxRedirects),
+		})
+	}
+
+	if e.RequireHTTPS {
+		assertions = append(assertions, checkHTTPS(result))
+	}
+
+	// Сообщение имеет смысл только для невыполненного ожидания
+	for i := range assertions {
+		if assertions[i].Passed {
+			assertions[i].Message = ""
+		}
+	}
+
+	result.Assertions = assertions
+}
+
+func checkStatus(ranges []types.StatusRange, code int) types.Assertion {
+	if len(ranges) == 0 {
+		ranges = []types.StatusRange{{Min: 200, Max: 299}}
+	}
+
+	for _, r := range ranges {
+		if r.Contains(code) {
+			return types.Assertion{Name: AssertStatus, Passed: true}
+		}
+	}
+
+	return types.Assertion{
+		Name:    AssertStatus,
+		Message: fmt.Sprintf("unexpected status code %d, expected %s", code, FormatStatusRanges(ranges)),
+	}
+}
+
+func checkHeader(h types.HeaderExpectation, header http.Header) types.Assertion {
+	values, ok := header[http.CanonicalHeaderKey(h.Name)]
+	if !ok {
+		return types.Assertion{
+			Name:    AssertHeader,
+			Message: fmt.Sprintf("header %s is missing", h.Name),
+		}
+	}
+
+	if h.Pattern != nil {
+		for _, v := range values {
+			if h.Pattern.MatchString(v) {
+				return types.Assertion{Name: AssertHeader, Passed: true}
+			}
+		}
+		return types.Assertion{
+			Name:    AssertHeader,
+			Message: fmt.Sprintf("header %s does not match /%s/", h.Name, h.Pattern),
+		}
+	}
+
+	return types.Assertion{Name: AssertHeader, Passed: true}
+}
+
+// checkHTTPS проверяет, что цепочка редиректов не уходит с https на http
+// и заканчивается https-адресом
+func checkHTTPS(result *types.Result) types.Assertion {
+	final := result.FinalURL
+	if final == "" {
+		final = result.URL
+	}
+
+	chain := make([]string, 0, len(result.Redirects)+1)
+	for _, hop := range result.Redirects {
+		chain = append(chain, hop.URL)
+	}
+	chain = append(chain, final)
+
+	for i := 1; i < len(chain); i++ {
+		if isHTTPS(chain[i-1]) && !isHTTPS(chain[i]) {
+			return types.Assertion{
+				Name:    AssertHTTPS,
+				Message: fmt.Sprintf("redirect from %s downgrades to %s", chain[i-1], chain[i]),
+			}
+		}
+	}
+
+	if !isHTTPS(final) {
+		return types.Assertion{
+			Name:    AssertHTTPS,
+			Message: fmt.Sprintf("final URL %s is not HTTPS", final),
+		}
+	}
+
+	return types.Assertion{Name: AssertHTTPS, Passed: true}
+}
+
+func isHTTPS(u string) bool {
+	return len(u) >= len("https://") && strings.EqualFold(u[:len("https://")], "https://")
+}
+
+// ParseStatusRanges разбирает список кодов через запятую. Поддерживаются
+// отдельные коды (301), диапазоны (200-299) и классы (2xx).
+func ParseStatusRanges(s string) ([]types.StatusRange, error) {
+	var ranges []types.StatusRange
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		r, err := parseStatusRange(part)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+
+	return ranges, nil
+}
+
+func parseStatusRange(s string) (types.StatusRange, error) {
+	if len(s) == 3 && strings.HasSuffix(strings.ToLower(s), "xx") {
+		class, err := strconv.Atoi(s[:1])
+		if err != nil || class < 1 || class > 5 {
+			return types.StatusRange{}, fmt.Errorf("invalid status class %q", s)
+		}
+		return types.StatusRange{Min: class * 100, Max: class*100 + 99}, nil
+	}
+
+	lo, hi, isRange := strings.Cut(s, "-")
+	low, err := parseStatusCode(lo)
+	if err != nil {
+		return types.StatusRange{}, err
+	}
+	if !isRange {
+		return types.StatusRange{Min: low, Max: low}, nil
+	}
+
+	high, err := parseStatusCode(hi)
+	if err != nil {
+		return types.StatusRange{}, err
+	}
+	if high < low {
+		return types.StatusRange{}, fmt.Errorf("invalid status range %q", s)
+	}
+
+	return types.StatusRange{Min: low, Max: high}, nil
+}
+
+func parseStatusCode(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", s)
+	}
+	return code, nil
+}
+
+// FormatStatusRanges возвращает диапазоны в том же виде, в каком их принимает ParseStatusRanges
+func FormatStatusRanges(ranges []types.StatusRange) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		switch {
+		case r.Min == r.Max:
+			parts[i] = strconv.Itoa(r.Min)
+		case r.Min%100 == 0 && r.Max == r.Min+99:
+			parts[i] = fmt.Sprintf("%dxx", r.Min/100)
+		default:
+			parts[i] = fmt.Sprintf("%d-%d", r.Min, r.Max)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseHeader разбирает ожидание заголовка вида "Name" или "Name: regexp"
+func ParseHeader(s string) (types.HeaderExpectation, error) {
+	name, pattern, hasPattern := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return types.HeaderExpectation{}, fmt.Errorf("empty header name in %q", s)
+	}
+
+	h := types.HeaderExpectation{Name: name}
+	if pattern = strings.TrimSpace(pattern); hasPattern && pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return types.HeaderExpectation{}, fmt.Errorf("invalid header pattern %q: %w", pattern, err)
+		}
+		h.Pattern = re
+	}
+
+	return h, nil
+}
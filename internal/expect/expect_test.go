@@ -0,0 +1,244 @@
+package expect
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func TestParseStatusRanges(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []types.StatusRange
+		wantErr  bool
+	}{
+		{"", nil, false},
+		{"200", []types.StatusRange{{Min: 200, Max: 200}}, false},
+		{"2xx, 301", []types.StatusRange{{Min: 200, Max: 299}, {Min: 301, Max: 301}}, false},
+		{"400-404", []types.StatusRange{{Min: 400, Max: 404}}, false},
+		{"404-400", nil, true},
+		{"6xx", nil, true},
+		{"abc", nil, true},
+		{"99", nil, true},
+	}
+
+	for _, tc := range testCases {
+		ranges, err := ParseStatusRanges(tc.input)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseStatusRanges(%q): expected error %v, got %v", tc.input, tc.wantErr, err)
+			continue
+		}
+		if len(ranges) != len(tc.expected) {
+			t.Errorf("ParseStatusRanges(%q): expected %v, got %v", tc.input, tc.expected, ranges)
+			continue
+		}
+		for i := range ranges {
+			if ranges[i] != tc.expected[i] {
+				t.Errorf("ParseStatusRanges(%q): expected %v, got %v", tc.input, tc.expected, ranges)
+			}
+		}
+	}
+}
+
+func TestFormatStatusRanges(t *testing.T) {
+	ranges := []types.StatusRange{{Min: 200, Max: 299}, {Min: 301, Max: 301}, {Min: 400, Max: 404}}
+	if got := FormatStatusRanges(ranges); got != "2xx,301,400-404" {
+		t.Errorf("Expected '2xx,301,400-404', got %q", got)
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	h, err := ParseHeader("Content-Type: ^application/json")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if h.Name != "Content-Type" || h.Pattern == nil || !h.Pattern.MatchString("application/json; charset=utf-8") {
+		t.Errorf("Unexpected header expectation: %+v", h)
+	}
+
+	h, err = ParseHeader("X-Request-Id")
+	if err != nil || h.Pattern != nil {
+		t.Errorf("Expected presence-only expectation, got %+v, %v", h, err)
+	}
+
+	if _, err := ParseHeader(": value"); err == nil {
+		t.Error("Expected error for empty header name")
+	}
+	if _, err := ParseHeader("X: ("); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestEvaluate_DefaultStatus(t *testing.T) {
+	ok := &types.Result{StatusCode: 204}
+	Evaluate(types.Expectations{}, ok)
+	if !ok.IsSuccess() || len(ok.Assertions) != 1 {
+		t.Errorf("Expected 204 to pass default expectations, got %+v", ok.Assertions)
+	}
+
+	redirect := &types.Result{StatusCode: 301}
+	Evaluate(types.Expectations{}, redirect)
+	if redirect.IsSuccess() {
+		t.Error("Expected 301 to fail default expectations")
+	}
+}
+
+func TestEvaluate_AllAssertions(t *testing.T) {
+	e := types.Expectations{
+		Statuses:        []types.StatusRange{{Min: 301, Max: 301}, {Min: 401, Max: 401}},
+		BodyContains:    []string{"healthy"},
+		BodyNotContains: []string{"error"},
+		BodyMatches:     []*regexp.Regexp{regexp.MustCompile(`version: \d+`)},
+		BodyNotMatches:  []*regexp.Regexp{regexp.MustCompile(`(?i)maintenance`)},
+		MaxLatency:      100 * time.Millisecond,
+		Headers: []types.HeaderExpectation{
+			{Name: "content-type", Pattern: regexp.MustCompile("json")},
+			{Name: "X-Missing"},
+		},
+	}
+
+	result := &types.Result{
+		StatusCode: 401,
+		Duration:   200 * time.Millisecond,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       []byte("healthy, version: 3, error count 0"),
+	}
+	Evaluate(e, result)
+
+	if len(result.Assertions) != 8 {
+		t.Fatalf("Expected 8 assertions, got %d", len(result.Assertions))
+	}
+
+	failed := map[string]bool{}
+	for _, a := range result.FailedAssertions() {
+		if a.Message == "" {
+			t.Errorf("Expected message for failed assertion %s", a.Name)
+		}
+		failed[a.Name] = true
+	}
+
+	expectedFailed := []string{AssertLatency, AssertBodyNotContains, AssertHeader}
+	if len(failed) != len(expectedFailed) {
+		t.Errorf("Expected failed %v, got %v", expectedFailed, result.FailedAssertions())
+	}
+	for _, name := range expectedFailed {
+		if !failed[name] {
+			t.Errorf("Expected %s to fail", name)
+		}
+	}
+}
+
+func TestEvaluate_Redirects(t *testing.T) {
+	testCases := []struct {
+		name      string
+		e         types.Expectations
+		redirects []types.Redirect
+		finalURL  string
+		failed    string
+	}{
+		{
+			name:      "http to https",
+			e:         types.Expectations{RequireHTTPS: true, MaxRedirects: 1},
+			redirects: []types.Redirect{{URL: "http://example.com", StatusCode: 301}},
+			finalURL:  "https://example.com",
+		},
+		{
+			name:     "no https redirect",
+			e:        types.Expectations{RequireHTTPS: true},
+			finalURL: "http://example.com",
+			failed:   AssertHTTPS,
+		},
+		{
+			name: "downgrade in chain",
+			e:    types.Expectations{RequireHTTPS: true},
+			redirects: []types.Redirect{
+				{URL: "https://example.com", StatusCode: 302},
+				{URL: "http://example.com/login", StatusCode: 302},
+			},
+			finalURL: "https://example.com/login",
+			failed:   AssertHTTPS,
+		},
+		{
+			name: "chain too long",
+			e:    types.Expectations{MaxRedirects: 1},
+			redirects: []types.Redirect{
+				{URL: "https://example.com", StatusCode: 301},
+				{URL: "https://www.example.com", StatusCode: 302},
+			},
+			finalURL: "https://www.example.com/en",
+			failed:   AssertRedirects,
+		},
+	}
+
+	for _, tc := range testCases {
+		result := &types.Result{StatusCode: 200, Redirects: tc.redirects, FinalURL: tc.finalURL}
+		Evaluate(tc.e, result)
+
+		failed := result.FailedAssertions()
+		switch {
+		case tc.failed == "" && len(failed) > 0:
+			t.Errorf("%s: expected no failures, got %+v", tc.name, failed)
+		case tc.failed != "" && (len(failed) != 1 || failed[0].Name != tc.failed):
+			t.Errorf("%s: expected %s to fail, got %+v", tc.name, tc.failed, failed)
+		}
+	}
+}
+
+func TestEvaluate_SkipsErrors(t *testing.T) {
+	result := &types.Result{Error: checker.ErrTimeout{}}
+	Evaluate(types.Expectations{}, result)
+
+	if result.Assertions != nil {
+		t.Errorf("Expected no assertions for errored result, got %+v", result.Assertions)
+	}
+}
+
+func TestChecker_BodyExpectations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": "ok"}`)
+	}))
+	defer server.Close()
+
+	c := NewChecker(checker.NewHTTPChecker())
+
+	pass := c.Check(context.Background(), types.Target{
+		URL:    server.URL,
+		Expect: types.Expectations{BodyContains: []string{`"ok"`}},
+	})
+	if !pass.IsSuccess() {
+		t.Errorf("Expected success, got %+v", pass.FailedAssertions())
+	}
+	if pass.Body != nil {
+		t.Error("Expected body to be released after evaluation")
+	}
+
+	fail := c.Check(context.Background(), types.Target{
+		URL:    server.URL,
+		Expect: types.Expectations{BodyContains: []string{"degraded"}},
+	})
+	if fail.IsSuccess() || len(fail.FailedAssertions()) != 1 {
+		t.Errorf("Expected one failed assertion, got %+v", fail.Assertions)
+	}
+}
+
+func TestChecker_KeepBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<a href="/docs">Docs</a>`)
+	}))
+	defer server.Close()
+
+	result := NewChecker(checker.NewHTTPChecker()).Check(context.Background(), types.Target{
+		URL:      server.URL,
+		KeepBody: true,
+	})
+	if string(result.Body) != `<a href="/docs">Docs</a>` {
+		t.Errorf("Expected body to be kept, got %q", result.Body)
+	}
+}
@@ -0,0 +1,110 @@
+package expect
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// Spec - ожидания в исходном текстовом виде, как они задаются во флагах
+// и файле конфигурации. Пустое поле означает "не задано".
+type Spec struct {
+	Status          string        `yaml:"status"`
+	BodyContains    []string      `yaml:"body_contains"`
+	BodyNotContains []string      `yaml:"body_not_contains"`
+	BodyMatches     []string      `yaml:"body_matches"`
+	BodyNotMatches  []string      `yaml:"body_not_matches"`
+	Headers         []string      `yaml:"headers"`
+	MaxLatency      time.Duration `yaml:"max_latency"`
+	MaxRedirects    int           `yaml:"max_redirects"`
+	HTTPS           *bool         `yaml:"https"`
+}
+
+// Merge возвращает копию s, в которой заданные в over поля заменяют исходные
+func (s Spec) Merge(over Spec) Spec {
+	if over.Status != "" {
+		s.Status = over.Status
+	}
+	if over.BodyContains != nil {
+		s.BodyContains = over.BodyContains
+	}
+	if over.BodyNotContains != nil {
+		s.BodyNotContains = over.BodyNotContains
+	}
+	if over.BodyMatches != nil {
+		s.BodyMatches = over.BodyMatches
+	}
+	if over.BodyNotMatches != nil {
+		s.BodyNotMatches = over.BodyNotMatches
+	}
+	if over.Headers != nil {
+		s.Headers = over.Headers
+	}
+	if over.MaxLatency != 0 {
+		s.MaxLatency = over.MaxLatency
+	}
+	if over.MaxRedirects != 0 {
+		s.MaxRedirects = over.MaxRedirects
+	}
+	if over.HTTPS != nil {
+		s.HTTPS = over.HTTPS
+	}
+	return s
+}
+
+// Compile проверяет Spec и строит из него types.Expectations.
+// Ошибки возвращаются как *types.FieldError.
+func (s Spec) Compile() (types.Expectations, error) {
+	var e types.Expectations
+
+	statuses, err := ParseStatusRanges(s.Status)
+	if err != nil {
+		return e, &types.FieldError{Field: "status", Err: err}
+	}
+	e.Statuses = statuses
+
+	e.BodyContains = s.BodyContains
+	e.BodyNotContains = s.BodyNotContains
+
+	if e.BodyMatches, err = compileAll(s.BodyMatches); err != nil {
+		return e, &types.FieldError{Field: "body_matches", Err: err}
+	}
+	if e.BodyNotMatches, err = compileAll(s.BodyNotMatches); err != nil {
+		return e, &types.FieldError{Field: "body_not_matches", Err: err}
+	}
+
+	for _, h := range s.Headers {
+		header, err := ParseHeader(h)
+		if err != nil {
+			return e, &types.FieldError{Field: "headers", Err: err}
+		}
+		e.Headers = append(e.Headers, header)
+	}
+
+	if s.MaxLatency < 0 {
+		return e, &types.FieldError{Field: "max_latency", Err: fmt.Errorf("must not be negative")}
+	}
+	e.MaxLatency = s.MaxLatency
+
+	if s.MaxRedirects < 0 {
+		return e, &types.FieldError{Field: "max_redirects", Err: fmt.Errorf("must not be negative")}
+	}
+	e.MaxRedirects = s.MaxRedirects
+	e.RequireHTTPS = s.HTTPS != nil && *s.HTTPS
+
+	return e, nil
+}
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
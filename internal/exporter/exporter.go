@@ -0,0 +1,237 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/metrics"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// shutdownTimeout - сколько ждать завершения начатых проб при остановке
+const shutdownTimeout = 5 * time.Second
+
+// Exporter - HTTP-сервер в духе blackbox exporter: /probe проверяет
+// цель по запросу Prometheus, /metrics отдает счетчики самого процесса
+type Exporter struct {
+	Checker checker.Checker
+
+	// Template - параметры запроса и ожидания для целей, не описанных в Targets
+	Template types.Target
+	// Targets - цели с собственными настройками, выбираются по URL
+	Targets []types.Target
+
+	// Version попадает в метрику urlcheck_build_info
+	Version string
+
+	mu       sync.Mutex
+	probes   int
+	failures map[string]int
+	started  time.Time
+}
+
+// Handler возвращает обработчик с /probe и /metrics
+func (e *Exporter) Handler() http.Handler {
+	e.mu.Lock()
+	if e.started.IsZero() {
+		e.started = time.Now()
+	}
+	e.mu.Unlock()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/probe", e.handleProbe)
+	mux.HandleFunc("/metrics", e.handleMetrics)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "urlcheck exporter\n\n/probe?target=https://example.com\n/metrics\n")
+	})
+	return mux
+}
+
+// ListenAndServe обслуживает запросы на addr, пока не будет отменен ctx
+func (e *Exporter) ListenAndServe(ctx context.Context, addr string) error {
+	server := &http.Server{Addr: addr, Handler: e.Handler()}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (e *Exporter) handleProbe(w http.ResponseWriter, r *http.Request) {
+	raw := r.URL.Query().Get("target")
+	u, err := url.Parse(raw)
+	if raw == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		http.Error(w, fmt.Sprintf("target parameter must be an http(s) URL, got %q", raw), http.StatusBadRequest)
+		return
+	}
+
+	// Prometheus сообщает таймаут опроса, проба должна уложиться в него
+	ctx := r.Context()
+	if s := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); s != "" {
+		if seconds, err := strconv.ParseFloat(s, 64); err == nil && seconds > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(seconds*float64(time.Second)))
+			defer cancel()
+		}
+	}
+
+	result := e.Checker.Check(ctx, e.target(raw))
+	e.record(*result)
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	metrics.Write(w, ProbeMetrics(*result))
+}
+
+func (e *Exporter) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	metrics.Write(w, e.processMetrics())
+}
+
+// target возвращает настройки цели по URL
+func (e *Exporter) target(rawURL string) types.Target {
+	for _, t := range e.Targets {
+		if t.URL == rawURL {
+			return t
+		}
+	}
+	t := e.Template
+	t.URL = rawURL
+	return t
+}
+
+func (e *Exporter) record(result types.Result) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.probes++
+	if result.IsSuccess() {
+		return
+	}
+
+	class := checker.ClassAssertion
+	if result.Error != nil {
+		class = checker.ErrorClass(result.Error)
+	}
+	if e.failures == nil {
+		e.failures = map[string]int{}
+	}
+	e.failures[class]++
+}
+
+func (e *Exporter) processMetrics() []metrics.Metric {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	failures := metrics.Metric{
+		Name: "urlcheck_probe_failures_total",
+		Help: "Failed probes by error class; failed expectations are counted as assertion",
+		Type: metrics.TypeCounter,
+	}
+	for _, class := range slices.Sorted(maps.Keys(e.failures)) {
+		failures.Samples = append(failures.Samples, metrics.Sample{
+			Labels: []metrics.Label{{Name: "class", Value: class}},
+			Value:  float64(e.failures[class]),
+		})
+	}
+
+	return []metrics.Metric{
+		metrics.Gauge("urlcheck_build_info", "Build information", 1,
+			metrics.Label{Name: "version", Value: e.Version}),
+		metrics.Gauge("urlcheck_start_time_seconds", "Start time of the exporter since unix epoch in seconds",
+			float64(e.started.Unix())),
+		{
+			Name:    "urlcheck_probes_total",
+			Help:    "Probes run since the exporter started",
+			Type:    metrics.TypeCounter,
+			Samples: []metrics.Sample{{Value: float64(e.probes)}},
+		},
+		failures,
+	}
+}
+
+// ProbeMetrics описывает результат одной пробы метриками
+func ProbeMetrics(result types.Result) []metrics.Metric {
+	ms := []metrics.Metric{
+		metrics.Gauge("urlcheck_probe_success", "Whether the check succeeded, including expectations",
+			metrics.Bool(result.IsSuccess())),
+		metrics.Gauge("urlcheck_probe_duration_seconds", "Time until the response headers were received",
+			result.Duration.Seconds()),
+		metrics.Gauge("urlcheck_probe_http_status_code", "Response status code, 0 if there was no response",
+			float64(result.StatusCode)),
+		metrics.Gauge("urlcheck_probe_redirects", "Number of redirects followed",
+			float64(len(result.Redirects))),
+	}
+
+	if t := result.Timing; t != (types.Timing{}) {
+		phases := metrics.Metric{
+			Name: "urlcheck_probe_phase_duration_seconds",
+			Help: "Duration of each request phase",
+			Type: metrics.TypeGauge,
+		}
+		for _, p := range []struct {
+			name string
+			d    time.Duration
+		}{
+			{"dns", t.DNS}, {"connect", t.Connect}, {"tls", t.TLS},
+			{"ttfb", t.TTFB}, {"transfer", t.Transfer},
+		} {
+			phases.Samples = append(phases.Samples, metrics.Sample{
+				Labels: []metrics.Label{{Name: "phase", Value: p.name}},
+				Value:  p.d.Seconds(),
+			})
+		}
+		ms = append(ms, phases)
+	}
+
+	if result.TLS != nil && len(result.TLS.Certificates) > 0 {
+		earliest := result.TLS.Certificates[0].NotAfter
+		for _, c := range result.TLS.Certificates[1:] {
+			if c.NotAfter.Before(earliest) {
+				earliest = c.NotAfter
+			}
+		}
+		ms = append(ms,
+			metrics.Gauge("urlcheck_probe_ssl_earliest_cert_expiry",
+				"Earliest expiry of the server certificate chain since unix epoch in seconds",
+				float64(earliest.Unix())),
+			metrics.Gauge("urlcheck_probe_tls_version_info", "TLS version of the connection", 1,
+				metrics.Label{Name: "version", Value: result.TLS.Version}),
+		)
+	}
+
+	if result.Error != nil {
+		ms = append(ms, metrics.Gauge("urlcheck_probe_error", "Class of the request error", 1,
+			metrics.Label{Name: "class", Value: checker.ErrorClass(result.Error)}))
+	}
+
+	return ms
+}
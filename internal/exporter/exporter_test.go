@@ -0,0 +1,152 @@
+package exporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/expect"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func newTestExporter() (*Exporter, *httptest.Server) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+
+	e := &Exporter{
+		Checker: expect.NewChecker(checker.NewHTTPChecker()),
+		Version: "1.0.0",
+		// /created ожидает 404, остальные - 2xx
+		Targets: []types.Target{{
+			URL:    backend.URL + "/created",
+			Expect: types.Expectations{Statuses: []types.StatusRange{{Min: 404, Max: 404}}},
+		}},
+	}
+	return e, backend
+}
+
+func get(t *testing.T, h http.Handler, target string) (int, string) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	body, _ := io.ReadAll(rec.Body)
+	return rec.Code, string(body)
+}
+
+func probe(t *testing.T, h http.Handler, target string) string {
+	code, body := get(t, h, "/probe?target="+url.QueryEscape(target))
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200 for probe of %s, got %d: %s", target, code, body)
+	}
+	return body
+}
+
+func TestExporter_Probe(t *testing.T) {
+	e, backend := newTestExporter()
+	defer backend.Close()
+	h := e.Handler()
+
+	body := probe(t, h, backend.URL+"/")
+	for _, line := range []string{
+		"urlcheck_probe_success 1\n",
+		"urlcheck_probe_http_status_code 200\n",
+		"# TYPE urlcheck_probe_duration_seconds gauge\n",
+		`urlcheck_probe_phase_duration_seconds{phase="ttfb"}`,
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected %q in probe output:\n%s", line, body)
+		}
+	}
+
+	if body := probe(t, h, backend.URL+"/missing"); !strings.Contains(body, "urlcheck_probe_success 0\n") {
+		t.Errorf("Expected failed probe for 404:\n%s", body)
+	}
+
+	// Настройки цели из Targets: 404 ожидается
+	if body := probe(t, h, backend.URL+"/created"); !strings.Contains(body, "urlcheck_probe_success 0\n") ||
+		!strings.Contains(body, "urlcheck_probe_http_status_code 200\n") {
+		t.Errorf("Expected target expectations to apply:\n%s", body)
+	}
+
+	body = probe(t, h, "http://127.0.0.1:1/")
+	if !strings.Contains(body, `urlcheck_probe_error{class="connection_refused"} 1`) {
+		t.Errorf("Expected error class in probe output:\n%s", body)
+	}
+
+	_, metrics := get(t, h, "/metrics")
+	for _, line := range []string{
+		"urlcheck_probes_total 4\n",
+		`urlcheck_probe_failures_total{class="assertion"} 2`,
+		`urlcheck_probe_failures_total{class="connection_refused"} 1`,
+		`urlcheck_build_info{version="1.0.0"} 1`,
+	} {
+		if !strings.Contains(metrics, line) {
+			t.Errorf("Expected %q in metrics:\n%s", line, metrics)
+		}
+	}
+}
+
+func TestExporter_BadTarget(t *testing.T) {
+	e, backend := newTestExporter()
+	defer backend.Close()
+
+	for _, target := range []string{"/probe", "/probe?target=ftp://a.com", "/probe?target=%3A%2F%2F"} {
+		if code, _ := get(t, e.Handler(), target); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", target, code)
+		}
+	}
+}
+
+func TestExporter_ScrapeTimeout(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer backend.Close()
+
+	e := &Exporter{Checker: checker.NewHTTPChecker()}
+
+	req := httptest.NewRequest(http.MethodGet, "/probe?target="+url.QueryEscape(backend.URL), nil)
+	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", "0.2")
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	e.Handler().ServeHTTP(rec, req)
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected probe to respect scrape timeout, took %v", elapsed)
+	}
+	if !strings.Contains(rec.Body.String(), "urlcheck_probe_success 0\n") {
+		t.Errorf("Expected failed probe:\n%s", rec.Body.String())
+	}
+}
+
+func TestExporter_ListenAndServe(t *testing.T) {
+	e := &Exporter{Checker: &checker.MockChecker{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- e.ListenAndServe(ctx, "127.0.0.1:0") }()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected clean shutdown, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected server to stop after cancel")
+	}
+}
@@ -3,22 +3,23 @@ package input
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"iter"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 )
 
 type Config struct {
-	File    string
-	URLs    []string
-	Stdin   bool
+	File  string
+	URLs  []string
+	Stdin bool
+	// MaxUrls ограничивает число URL; 0 - без ограничения
 	MaxUrls int
 }
 
 func NewConfig(file string, urls []string, stdin bool, maxUrls int) *Config {
-	if maxUrls < 0 {
-		maxUrls = 10000
-	}
-
 	if urls == nil {
 		urls = []string{}
 	}
@@ -31,45 +32,91 @@ func NewConfig(file string, urls []string, stdin bool, maxUrls int) *Config {
 	}
 }
 
-func (cfg *Config) GetURLs() ([]string, error) {
+// Reader выдает URL из источника по одному, не загружая его целиком в память
+type Reader struct {
+	urls    []string
+	scanner *bufio.Scanner
+	closer  io.Closer
+	name    string
+	limit   int
+
+	// mu защищает err: обход может идти в другой горутине,
+	// пока Err уже вызван после досрочной остановки
+	mu  sync.Mutex
+	err error
+}
+
+// Open открывает источник URL. Reader нужно закрыть после использования.
+func (cfg *Config) Open() (*Reader, error) {
 	if len(cfg.URLs) > 0 {
-		return cfg.URLs, nil
+		return &Reader{urls: cfg.URLs, limit: cfg.MaxUrls}, nil
 	}
 	if cfg.File != "" {
-		return readURLsFromFile(cfg.File, cfg.MaxUrls)
+		file, err := os.Open(cfg.File)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка открытия файла %q: %w", cfg.File, err)
+		}
+		return &Reader{
+			scanner: bufio.NewScanner(file),
+			closer:  file,
+			name:    fmt.Sprintf("файла %q", cfg.File),
+			limit:   cfg.MaxUrls,
+		}, nil
 	}
 	if cfg.Stdin {
-		return readURLsFromStdin(cfg.MaxUrls)
+		return &Reader{
+			scanner: bufio.NewScanner(os.Stdin),
+			name:    "stdin",
+			limit:   cfg.MaxUrls,
+		}, nil
 	}
 	return nil, fmt.Errorf("не указаны URL, файл или флаг --stdin")
 }
 
-func readURLsFromFile(filename string, limit int) ([]string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка открытия файла %q: %w", filename, err)
+// Total возвращает число URL, если оно известно заранее, иначе 0
+func (r *Reader) Total() int {
+	if r.scanner != nil {
+		return 0
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	urls := readFilterLines(scanner, limit)
+	if r.limit > 0 && r.limit < len(r.urls) {
+		return r.limit
+	}
+	return len(r.urls)
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("ошибка чтения файла %q: %w", filename, err)
+// URLs возвращает последовательность URL источника. Ошибка чтения
+// доступна через Err после завершения обхода.
+func (r *Reader) URLs() iter.Seq[string] {
+	if r.scanner == nil {
+		return slices.Values(r.urls[:r.Total()])
 	}
 
-	return urls, nil
+	return func(yield func(string) bool) {
+		for line := range filterLines(r.scanner, r.limit) {
+			if !yield(line) {
+				return
+			}
+		}
+		if err := r.scanner.Err(); err != nil {
+			r.mu.Lock()
+			r.err = fmt.Errorf("ошибка чтения %s: %w", r.name, err)
+			r.mu.Unlock()
+		}
+	}
 }
 
-func readURLsFromStdin(limit int) ([]string, error) {
-	scanner := bufio.NewScanner(os.Stdin)
-	urls := readFilterLines(scanner, limit)
+// Err возвращает ошибку чтения источника
+func (r *Reader) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("ошибка чтения stdin: %w", err)
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
 	}
-
-	return urls, nil
+	return r.closer.Close()
 }
 
 func processLine(line string) (string, bool) {
@@ -82,16 +129,23 @@ func processLine(line string) (string, bool) {
 	return line, true
 }
 
-func readFilterLines(s *bufio.Scanner, limit int) []string {
-	var urls []string
-	for s.Scan() {
-		if line, valid := processLine(s.Text()); valid {
-			if len(urls) == limit {
-				break
-			} else {
-				urls = append(urls, line)
+// filterLines выдает непустые строки без комментариев, не более limit
+// (0 - без ограничения)
+func filterLines(s *bufio.Scanner, limit int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		count := 0
+		for s.Scan() {
+			line, valid := processLine(s.Text())
+			if !valid {
+				continue
+			}
+			if limit > 0 && count == limit {
+				return
+			}
+			count++
+			if !yield(line) {
+				return
 			}
 		}
 	}
-	return urls
 }
@@ -3,37 +3,30 @@ package input
 import (
 	"bufio"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 )
 
-func TestNewConfig_Basic(t *testing.T) {
-	filename := ""
-	urls := []string{"a", "b", "c"}
-	limit := 10
-
-	cfg := NewConfig(filename, urls, false, limit)
-
-	if cfg.File != filename {
-		t.Errorf("Expected %s, got %s", filename, cfg.File)
-	}
-	for i, url := range cfg.URLs {
-		if urls[i] != url {
-			t.Errorf("Expected %s, got %s", urls[i], url)
-		}
-	}
-	if cfg.Stdin {
-		t.Errorf("Expected false, got true")
+// readAll читает все URL источника, как это делает обход в CLI
+func readAll(cfg *Config) ([]string, error) {
+	r, err := cfg.Open()
+	if err != nil {
+		return nil, err
 	}
-	if cfg.MaxUrls != limit {
-		t.Errorf("Expected %d, got %d", limit, cfg.MaxUrls)
+	defer r.Close()
+
+	urls := slices.Collect(r.URLs())
+	if err := r.Err(); err != nil {
+		return nil, err
 	}
+	return urls, nil
 }
 
-func TestNewConfig_DefaultLimit(t *testing.T) {
+func TestNewConfig_Basic(t *testing.T) {
 	filename := ""
 	urls := []string{"a", "b", "c"}
-	limit := -1
-	expectedLimit := 10000
+	limit := 10
 
 	cfg := NewConfig(filename, urls, false, limit)
 
@@ -48,8 +41,8 @@ func TestNewConfig_DefaultLimit(t *testing.T) {
 	if cfg.Stdin {
 		t.Errorf("Expected false, got true")
 	}
-	if cfg.MaxUrls != expectedLimit {
-		t.Errorf("Expected %d, got %d", expectedLimit, cfg.MaxUrls)
+	if cfg.MaxUrls != limit {
+		t.Errorf("Expected %d, got %d", limit, cfg.MaxUrls)
 	}
 }
 
@@ -73,13 +66,13 @@ func TestNewConfig_DefaultUrls(t *testing.T) {
 	}
 }
 
-func TestGetURLs_URLsOnly(t *testing.T) {
+func TestReadAll_URLsOnly(t *testing.T) {
 	filename := ""
 	exp_urls := []string{"a", "b", "c"}
 	limit := 10
 
 	cfg := NewConfig(filename, exp_urls, false, limit)
-	urls, err := cfg.GetURLs()
+	urls, err := readAll(cfg)
 
 	if err != nil {
 		t.Errorf("Expected nil as error, got %s", err)
@@ -91,7 +84,7 @@ func TestGetURLs_URLsOnly(t *testing.T) {
 	}
 }
 
-func TestGetURLs_FileOnly(t *testing.T) {
+func TestReadAll_FileOnly(t *testing.T) {
 	content := "http://example.com\n# комментарий\nhttp://google.com\n"
 	exp_urls := []string{"http://example.com", "http://google.com"}
 
@@ -109,7 +102,7 @@ func TestGetURLs_FileOnly(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	urls, err := NewConfig(tmpFile.Name(), nil, false, 10).GetURLs()
+	urls, err := readAll(NewConfig(tmpFile.Name(), nil, false, 10))
 	if err != nil {
 		t.Errorf("Expected nil as error, got %s", err)
 	}
@@ -121,7 +114,7 @@ func TestGetURLs_FileOnly(t *testing.T) {
 	}
 }
 
-func TestGetURLs_StdinOnly(t *testing.T) {
+func TestReadAll_StdinOnly(t *testing.T) {
 	content := "http://example.com\n# комментарий\nhttp://google.com\n"
 	exp_urls := []string{"http://example.com", "http://google.com"}
 
@@ -145,7 +138,7 @@ func TestGetURLs_StdinOnly(t *testing.T) {
 
 	os.Stdin = tmpFile
 
-	urls, err := NewConfig("", nil, true, 10).GetURLs()
+	urls, err := readAll(NewConfig("", nil, true, 10))
 	if err != nil {
 		t.Errorf("Expected nil as error, got %s", err)
 	}
@@ -157,8 +150,8 @@ func TestGetURLs_StdinOnly(t *testing.T) {
 	}
 }
 
-func TestGetUrls_NoSource(t *testing.T) {
-	urls, err := NewConfig("", nil, false, 0).GetURLs()
+func TestReadAll_NoSource(t *testing.T) {
+	urls, err := readAll(NewConfig("", nil, false, 0))
 
 	if err == nil {
 		t.Error("Expected error, got nil")
@@ -169,8 +162,8 @@ func TestGetUrls_NoSource(t *testing.T) {
 	}
 }
 
-func TestGetUrls_NoFile(t *testing.T) {
-	urls, err := NewConfig("test.t", nil, false, 0).GetURLs()
+func TestReadAll_NoFile(t *testing.T) {
+	urls, err := readAll(NewConfig("test.t", nil, false, 0))
 
 	if err == nil {
 		t.Error("Expected error, got nil")
@@ -181,7 +174,7 @@ func TestGetUrls_NoFile(t *testing.T) {
 	}
 }
 
-func TestGetUrls_EmptyFile(t *testing.T) {
+func TestReadAll_EmptyFile(t *testing.T) {
 	tmpFile, err := os.CreateTemp("", "test-urls-*.txt")
 	if err != nil {
 		t.Fatal(err)
@@ -189,7 +182,7 @@ func TestGetUrls_EmptyFile(t *testing.T) {
 	defer os.Remove(tmpFile.Name())
 	tmpFile.Close()
 
-	urls, err := NewConfig(tmpFile.Name(), nil, true, 0).GetURLs()
+	urls, err := readAll(NewConfig(tmpFile.Name(), nil, true, 0))
 
 	if err != nil {
 		t.Errorf("Expected nil, got '%s'", err)
@@ -200,8 +193,8 @@ func TestGetUrls_EmptyFile(t *testing.T) {
 	}
 }
 
-func TestGetUrls_EmtyStdin(t *testing.T) {
-	urls, err := NewConfig("", nil, true, 0).GetURLs()
+func TestReadAll_EmtyStdin(t *testing.T) {
+	urls, err := readAll(NewConfig("", nil, true, 0))
 
 	if err != nil {
 		t.Errorf("Expected nil, got '%s'", err)
@@ -235,7 +228,7 @@ func TestReadFiltredLines_Limit(t *testing.T) {
 	}
 
 	scanner := bufio.NewScanner(file)
-	urls := readFilterLines(scanner, 1)
+	urls := slices.Collect(filterLines(scanner, 1))
 
 	if len(urls) > 1 {
 		t.Errorf("Expected slice with len 1, got len %d", len(urls))
@@ -277,3 +270,128 @@ func TestProcessLine_WithSpaces(t *testing.T) {
 		t.Errorf("Expected valid, got false")
 	}
 }
+
+func TestReader_StreamsFileWithoutLimit(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test-urls-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	for i := 0; i < 20000; i++ {
+		if _, err := tmpFile.WriteString("http://example.com\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tmpFile.Close()
+
+	r, err := NewConfig(tmpFile.Name(), nil, false, 0).Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if r.Total() != 0 {
+		t.Errorf("Expected unknown total for file, got %d", r.Total())
+	}
+
+	count := 0
+	for range r.URLs() {
+		count++
+	}
+
+	if r.Err() != nil {
+		t.Errorf("Expected nil error, got %v", r.Err())
+	}
+	if count != 20000 {
+		t.Errorf("Expected 20000 URLs, got %d", count)
+	}
+}
+
+func TestReader_StopsEarly(t *testing.T) {
+	content := "http://a.com\nhttp://b.com\nhttp://c.com\n"
+
+	tmpFile, err := os.CreateTemp("", "test-urls-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.WriteString(content)
+	tmpFile.Close()
+
+	r, err := NewConfig(tmpFile.Name(), nil, false, 0).Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var urls []string
+	for u := range r.URLs() {
+		urls = append(urls, u)
+		if len(urls) == 2 {
+			break
+		}
+	}
+
+	if len(urls) != 2 || urls[1] != "http://b.com" {
+		t.Errorf("Expected first two URLs, got %v", urls)
+	}
+}
+
+func TestReader_TotalForURLList(t *testing.T) {
+	urls := []string{"a", "b", "c"}
+
+	r, err := NewConfig("", urls, false, 2).Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Total() != 2 {
+		t.Errorf("Expected total 2, got %d", r.Total())
+	}
+
+	count := 0
+	for range r.URLs() {
+		count++
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 URLs, got %d", count)
+	}
+}
+
+func TestReader_ErrDuringIteration(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test-urls-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+	// Строка длиннее буфера сканера завершает чтение с ошибкой
+	tmpFile.WriteString("http://a.com\n" + strings.Repeat("a", bufio.MaxScanTokenSize+1) + "\n")
+	tmpFile.Close()
+
+	r, err := NewConfig(tmpFile.Name(), nil, false, 0).Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range r.URLs() {
+		}
+	}()
+
+	// Err можно вызывать, пока обход еще идет
+	for {
+		select {
+		case <-done:
+			if r.Err() == nil {
+				t.Error("Expected read error, got nil")
+			}
+			return
+		default:
+			r.Err()
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Типы метрик
+const (
+	TypeGauge   = "gauge"
+	TypeCounter = "counter"
+)
+
+// Metric - метрика в текстовом формате Prometheus со всеми ее значениями
+type Metric struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Sample - одно значение метрики с метками
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Gauge создает gauge с одним значением
+func Gauge(name, help string, value float64, labels ...Label) Metric {
+	return Metric{Name: name, Help: help, Type: TypeGauge, Samples: []Sample{{Labels: labels, Value: value}}}
+}
+
+// Bool переводит логическое значение в 0 или 1
+func Bool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Write выводит метрики в текстовом формате Prometheus (exposition format 0.0.4)
+func Write(w io.Writer, metrics []Metric) error {
+	bw := bufio.NewWriter(w)
+
+	for _, m := range metrics {
+		if len(m.Samples) == 0 {
+			continue
+		}
+
+		bw.WriteString("# HELP " + m.Name + " " + escapeHelp(m.Help) + "\n")
+		bw.WriteString("# TYPE " + m.Name + " " + m.Type + "\n")
+
+		for _, s := range m.Samples {
+			bw.WriteString(m.Name)
+			if len(s.Labels) > 0 {
+				bw.WriteByte('{')
+				for i, l := range s.Labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					bw.WriteString(l.Name + `="` + escapeLabel(l.Value) + `"`)
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteString(" " + formatValue(s.Value) + "\n")
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ContentType - тип содержимого для ответа с метриками
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
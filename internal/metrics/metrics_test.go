@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, []Metric{
+		Gauge("urlcheck_up", "Whether the target\nis up", 1),
+		{
+			Name: "urlcheck_failures_total",
+			Help: `Failures by class \ reason`,
+			Type: TypeCounter,
+			Samples: []Sample{
+				{Labels: []Label{{"class", "timeout"}}, Value: 3},
+				{Labels: []Label{{"class", `say "hi"`}, {"url", "http://a\\b"}}, Value: 0.25},
+			},
+		},
+		{Name: "urlcheck_empty", Help: "Skipped", Type: TypeGauge},
+		Gauge("urlcheck_nan", "Not a number", math.NaN()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP urlcheck_up Whether the target\nis up
+# TYPE urlcheck_up gauge
+urlcheck_up 1
+# HELP urlcheck_failures_total Failures by class \\ reason
+# TYPE urlcheck_failures_total counter
+urlcheck_failures_total{class="timeout"} 3
+urlcheck_failures_total{class="say \"hi\"",url="http://a\\b"} 0.25
+# HELP urlcheck_nan Not a number
+# TYPE urlcheck_nan gauge
+urlcheck_nan NaN
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,295 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/output"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// DefaultTemplate - тело уведомления по умолчанию. Поле text понимают
+// входящие вебхуки Slack и Teams, в result передается весь результат.
+const DefaultTemplate = `{"text": {{json .Text}}, "time": {{json .Time}}, "result": {{json .JSONResult}}}`
+
+// maxInFlight - число горутин, отправляющих уведомления
+const maxInFlight = 4
+
+// maxQueued ограничивает очередь уведомлений; при переполнении новые
+// уведомления отбрасываются и учитываются в ошибке Wait
+const maxQueued = 100
+
+// minSweep - размер sent, начиная с которого из него удаляются
+// истекшие записи
+const minSweep = 1000
+
+// Event - данные для шаблона уведомления: поля output.JSONResult
+// (.URL, .StatusCode, .ErrorClass, ...), готовый текст и время проверки
+type Event struct {
+	output.JSONResult
+	Text string
+	Time time.Time
+}
+
+// ParseTemplate разбирает шаблон тела уведомления. В шаблоне доступна
+// функция json, которая кодирует значение как JSON.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").
+		Funcs(template.FuncMap{"json": toJSON}).
+		Option("missingkey=error").
+		Parse(text)
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// Webhook отправляет POST-запрос с JSON для каждой неуспешной проверки.
+// Отправка идет в фоне из очереди ограниченного размера, чтобы не задерживать
+// проверки; Wait дожидается ее завершения, после него Notify не отправляет.
+type Webhook struct {
+	URL      string
+	Template *template.Template
+	Client   *http.Client
+
+	// Retries - число повторов при сетевой ошибке, 429 или 5xx;
+	// RetryDelay - пауза перед первым повтором, далее удваивается
+	Retries    int
+	RetryDelay time.Duration
+
+	// Mute - сколько не отправлять повторные уведомления об одной цели
+	Mute time.Duration
+
+	// OnError, если задан, получает ошибки отправки сразу, иначе первая
+	// ошибка возвращается из Wait. Вызывается из разных горутин.
+	OnError func(error)
+
+	mu      sync.Mutex
+	sent    map[string]time.Time
+	sweepAt int
+	queue   chan delivery
+	closed  bool
+	dropped int
+	err     error
+	wg      sync.WaitGroup
+	now     func() time.Time
+}
+
+// delivery - уведомление в очереди на отправку
+type delivery struct {
+	ctx     context.Context
+	url     string
+	payload []byte
+}
+
+// NewWebhook создает notifier с шаблоном по умолчанию
+func NewWebhook(url string) *Webhook {
+	tmpl, err := ParseTemplate(DefaultTemplate)
+	if err != nil {
+		panic(err)
+	}
+	return &Webhook{
+		URL:        url,
+		Template:   tmpl,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		Retries:    2,
+		RetryDelay: time.Second,
+	}
+}
+
+// Notify ставит в очередь уведомление, если проверка неуспешна
+// и цель не заглушена
+func (w *Webhook) Notify(ctx context.Context, result types.Result) {
+	if result.IsSuccess() {
+		return
+	}
+
+	now := w.clock()
+	if w.muted(result.URL, now) {
+		return
+	}
+
+	payload, err := w.render(result, now)
+	if err != nil {
+		w.fail(err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	if w.queue == nil {
+		w.start()
+	}
+	select {
+	case w.queue <- delivery{ctx: ctx, url: result.URL, payload: payload}:
+	default:
+		w.dropped++
+	}
+}
+
+// muted сообщает, отправлялось ли уведомление об url в течение Mute,
+// и иначе запоминает время отправки
+func (w *Webhook) muted(url string, now time.Time) bool {
+	if w.Mute <= 0 {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.sent == nil {
+		w.sent = map[string]time.Time{}
+		w.sweepAt = minSweep
+	}
+	if last, ok := w.sent[url]; ok && now.Sub(last) < w.Mute {
+		return true
+	}
+	w.sent[url] = now
+
+	// Истекшие записи больше не глушат уведомления, поэтому удаляются,
+	// чтобы память не росла с числом разных URL
+	if len(w.sent) >= w.sweepAt {
+		for u, last := range w.sent {
+			if now.Sub(last) >= w.Mute {
+				delete(w.sent, u)
+			}
+		}
+		w.sweepAt = max(2*len(w.sent), minSweep)
+	}
+	return false
+}
+
+// start запускает горутины отправки; вызывается под mu
+func (w *Webhook) start() {
+	w.queue = make(chan delivery, maxQueued)
+	w.wg.Add(maxInFlight)
+	for i := 0; i < maxInFlight; i++ {
+		go func() {
+			defer w.wg.Done()
+			for d := range w.queue {
+				if err := w.send(d.ctx, d.payload); err != nil {
+					w.fail(fmt.Errorf("webhook for %s: %w", d.url, err))
+				}
+			}
+		}()
+	}
+}
+
+// Wait дожидается отправки всех уведомлений и возвращает первую ошибку;
+// отброшенные из-за переполнения очереди уведомления тоже считаются ошибкой
+func (w *Webhook) Wait() error {
+	w.mu.Lock()
+	if !w.closed && w.queue != nil {
+		close(w.queue)
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil && w.dropped > 0 {
+		return fmt.Errorf("webhook queue full, %d notifications dropped", w.dropped)
+	}
+	return w.err
+}
+
+// Validate проверяет, что шаблон дает корректный JSON, на примере
+// неуспешной проверки, чтобы ошибка обнаружилась до начала работы
+func (w *Webhook) Validate() error {
+	_, err := w.render(types.Result{URL: "https://example.com", StatusCode: http.StatusServiceUnavailable}, time.Now())
+	return err
+}
+
+func (w *Webhook) render(result types.Result, now time.Time) ([]byte, error) {
+	event := Event{
+		JSONResult: output.NewJSONResult(result),
+		Text:       fmt.Sprintf("✗ %s failed (%s)", result.URL, output.FailureReason(result)),
+		Time:       now,
+	}
+
+	var buf bytes.Buffer
+	if err := w.Template.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("webhook template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook template produced invalid JSON: %s", buf.String())
+	}
+
+	return buf.Bytes(), nil
+}
+
+// send отправляет payload, повторяя попытку при временных сбоях
+func (w *Webhook) send(ctx context.Context, payload []byte) error {
+	delay := w.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, payload)
+		if err == nil || !retry || attempt >= w.Retries {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// post выполняет один запрос; retry сообщает, имеет ли смысл повтор
+func (w *Webhook) post(ctx context.Context, payload []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+}
+
+func (w *Webhook) fail(err error) {
+	if w.OnError != nil {
+		w.OnError(err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *Webhook) clock() time.Time {
+	if w.now != nil {
+		return w.now()
+	}
+	return time.Now()
+}
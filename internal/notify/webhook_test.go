@@ -0,0 +1,260 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// hookServer - стенд вебхука: отвечает кодами из statuses по очереди
+// (затем 200) и сохраняет полученные тела
+type hookServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	bodies   [][]byte
+}
+
+func newHookServer(t *testing.T, statuses ...int) *hookServer {
+	s := &hookServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.bodies = append(s.bodies, body)
+		if len(s.statuses) > 0 {
+			w.WriteHeader(s.statuses[0])
+			s.statuses = s.statuses[1:]
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *hookServer) requests() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bodies
+}
+
+func failedResult(url string) types.Result {
+	return types.Result{URL: url, StatusCode: 503, Duration: 40 * time.Millisecond}
+}
+
+func TestWebhook_DefaultPayload(t *testing.T) {
+	server := newHookServer(t)
+	hook := NewWebhook(server.URL)
+
+	hook.Notify(context.Background(), types.Result{URL: "http://ok.com", StatusCode: 200})
+	hook.Notify(context.Background(), types.Result{
+		URL:   "http://down.com",
+		Error: checker.ErrTimeout{URL: "http://down.com"},
+	})
+
+	if err := hook.Wait(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	requests := server.requests()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 notification for the failed check, got %d", len(requests))
+	}
+
+	var payload struct {
+		Text   string `json:"text"`
+		Result struct {
+			URL        string `json:"url"`
+			ErrorClass string `json:"error_class"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(requests[0], &payload); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, requests[0])
+	}
+	if payload.Text == "" || payload.Result.URL != "http://down.com" || payload.Result.ErrorClass != checker.ClassTimeout {
+		t.Errorf("Unexpected payload: %s", requests[0])
+	}
+}
+
+func TestWebhook_CustomTemplate(t *testing.T) {
+	server := newHookServer(t)
+	hook := NewWebhook(server.URL)
+
+	var err error
+	hook.Template, err = ParseTemplate(`{"content": {{json (printf "%s returned %d" .URL .StatusCode)}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook.Notify(context.Background(), failedResult("http://down.com"))
+	if err := hook.Wait(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `{"content": "http://down.com returned 503"}`
+	if requests := server.requests(); len(requests) != 1 || string(requests[0]) != expected {
+		t.Errorf("Expected %s, got %q", expected, requests)
+	}
+}
+
+func TestWebhook_Validate(t *testing.T) {
+	testCases := []struct {
+		template string
+		valid    bool
+	}{
+		{DefaultTemplate, true},
+		{`{"url": {{json .URL}}}`, true},
+		{`{"url": {{.URL}}}`, false},
+		{`{"url": {{json .Missing}}}`, false},
+	}
+
+	for _, tc := range testCases {
+		tmpl, err := ParseTemplate(tc.template)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.template, err)
+		}
+
+		hook := NewWebhook("http://hooks.local")
+		hook.Template = tmpl
+
+		if err := hook.Validate(); (err == nil) != tc.valid {
+			t.Errorf("%s: expected valid %v, got %v", tc.template, tc.valid, err)
+		}
+	}
+}
+
+func TestWebhook_Retry(t *testing.T) {
+	testCases := []struct {
+		name     string
+		statuses []int
+		requests int
+		fails    bool
+	}{
+		{"recovers after server errors", []int{503, 429}, 3, false},
+		{"gives up after retries", []int{500, 500, 500, 500}, 3, true},
+		{"does not retry client errors", []int{400}, 1, true},
+	}
+
+	for _, tc := range testCases {
+		server := newHookServer(t, tc.statuses...)
+		hook := NewWebhook(server.URL)
+		hook.RetryDelay = time.Millisecond
+
+		hook.Notify(context.Background(), failedResult("http://down.com"))
+		err := hook.Wait()
+
+		if got := len(server.requests()); got != tc.requests {
+			t.Errorf("%s: expected %d requests, got %d", tc.name, tc.requests, got)
+		}
+		if (err != nil) != tc.fails {
+			t.Errorf("%s: expected error %v, got %v", tc.name, tc.fails, err)
+		}
+	}
+}
+
+func TestWebhook_Mute(t *testing.T) {
+	server := newHookServer(t)
+	hook := NewWebhook(server.URL)
+	hook.Mute = time.Minute
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	hook.now = func() time.Time { return now }
+
+	notify := func(url string) {
+		hook.Notify(context.Background(), failedResult(url))
+	}
+
+	notify("http://a.com")
+	notify("http://b.com") // другие цели не заглушены
+	now = now.Add(30 * time.Second)
+	notify("http://a.com") // в пределах окна
+	now = now.Add(31 * time.Second)
+	notify("http://a.com") // окно прошло
+
+	if err := hook.Wait(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := len(server.requests()); got != 3 {
+		t.Errorf("Expected 3 notifications, got %d", got)
+	}
+}
+
+func TestWebhook_OnError(t *testing.T) {
+	server := newHookServer(t, 400)
+	hook := NewWebhook(server.URL)
+
+	var reported []error
+	hook.OnError = func(err error) { reported = append(reported, err) }
+
+	hook.Notify(context.Background(), failedResult("http://down.com"))
+
+	if err := hook.Wait(); err != nil {
+		t.Errorf("Expected error to go to OnError, got %v from Wait", err)
+	}
+	if len(reported) != 1 {
+		t.Errorf("Expected 1 reported error, got %v", reported)
+	}
+}
+
+func TestWebhook_MuteForgetsExpired(t *testing.T) {
+	hook := NewWebhook("http://127.0.0.1:1")
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3*minSweep; i++ {
+		if hook.muted(fmt.Sprintf("http://host%d.com", i), now) {
+			t.Fatal("Expected no muting without Mute")
+		}
+	}
+	if hook.sent != nil {
+		t.Errorf("Expected nothing recorded without Mute, got %d entries", len(hook.sent))
+	}
+
+	hook.Mute = time.Minute
+	for i := 0; i < 3*minSweep; i++ {
+		now = now.Add(time.Second)
+		hook.muted(fmt.Sprintf("http://host%d.com", i), now)
+	}
+	if len(hook.sent) > minSweep {
+		t.Errorf("Expected expired entries to be removed, got %d", len(hook.sent))
+	}
+	if !hook.muted(fmt.Sprintf("http://host%d.com", 3*minSweep-1), now) {
+		t.Error("Expected recent URL to stay muted")
+	}
+}
+
+func TestWebhook_QueueOverflow(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	hook := NewWebhook(server.URL)
+	const total = maxInFlight + maxQueued + 50
+	for i := 0; i < total; i++ {
+		hook.Notify(context.Background(), failedResult(fmt.Sprintf("http://host%d.com", i)))
+	}
+
+	// Очередь ограничена, и лишние уведомления не держат горутины
+	if n := runtime.NumGoroutine(); n > 50 {
+		t.Errorf("Expected a bounded number of goroutines, got %d", n)
+	}
+
+	close(release)
+	err := hook.Wait()
+	if err == nil || !strings.Contains(err.Error(), "notifications dropped") {
+		t.Errorf("Expected dropped notifications to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// Колонки табличного вывода
+const (
+	ColumnURL           = "url"
+	ColumnMethod        = "method"
+	ColumnStatus        = "status"
+	ColumnDuration      = "duration_ms"
+	ColumnSuccess       = "success"
+	ColumnErrorClass    = "error_class"
+	ColumnError         = "error"
+	ColumnFinalURL      = "final_url"
+	ColumnContentLength = "content_length"
+	ColumnTimestamp     = "timestamp"
+	ColumnReferrer      = "referrer"
+)
+
+// Columns - все доступные колонки в порядке по умолчанию
+var Columns = []string{
+	ColumnURL, ColumnMethod, ColumnStatus, ColumnDuration, ColumnSuccess,
+	ColumnErrorClass, ColumnError, ColumnFinalURL, ColumnContentLength, ColumnTimestamp,
+	ColumnReferrer,
+}
+
+// DefaultColumns - колонки, которые выводятся, если набор не указан
+var DefaultColumns = []string{
+	ColumnURL, ColumnStatus, ColumnDuration, ColumnErrorClass,
+	ColumnFinalURL, ColumnContentLength, ColumnTimestamp,
+}
+
+// ParseColumns разбирает список колонок через запятую;
+// пустая строка означает DefaultColumns
+func ParseColumns(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultColumns, nil
+	}
+
+	var columns []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(Columns, name) {
+			return nil, fmt.Errorf("unknown column %q. Use %s", name, strings.Join(Columns, ", "))
+		}
+		columns = append(columns, name)
+	}
+	return columns, nil
+}
+
+// CSVWriter выводит результаты таблицей с заголовком: CSV или, с
+// разделителем '\t', TSV. Итоги в таблицу не попадают.
+type CSVWriter struct {
+	w       *csv.Writer
+	columns []string
+	header  bool
+	err     error
+}
+
+func NewCSVWriter(w io.Writer, comma rune, columns []string) *CSVWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	return &CSVWriter{w: cw, columns: columns}
+}
+
+func (w *CSVWriter) WriteProgress(current, total int, result types.Result) {
+	w.writeHeader()
+
+	record := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		record[i] = w.value(column, result)
+	}
+	w.write(record)
+
+	// Строки сбрасываются сразу, чтобы таблицу можно было читать по ходу проверки
+	w.w.Flush()
+}
+
+func (w *CSVWriter) WriteSummary(summary Summary) {
+	// Заголовок выводится и для пустой таблицы
+	w.writeHeader()
+	w.w.Flush()
+}
+
+// Err возвращает первую ошибку записи
+func (w *CSVWriter) Err() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Error()
+}
+
+func (w *CSVWriter) writeHeader() {
+	if w.header {
+		return
+	}
+	w.header = true
+	w.write(w.columns)
+}
+
+func (w *CSVWriter) write(record []string) {
+	if w.err != nil {
+		return
+	}
+	w.err = w.w.Write(record)
+}
+
+func (w *CSVWriter) value(column string, result types.Result) string {
+	switch column {
+	case ColumnURL:
+		return result.URL
+	case ColumnMethod:
+		return result.Method
+	case ColumnStatus:
+		if result.StatusCode == 0 {
+			return ""
+		}
+		return strconv.Itoa(result.StatusCode)
+	case ColumnDuration:
+		return strconv.FormatFloat(durationMs(result.Duration), 'f', -1, 64)
+	case ColumnSuccess:
+		return strconv.FormatBool(result.IsSuccess())
+	case ColumnErrorClass:
+		if result.Error != nil {
+			return checker.ErrorClass(result.Error)
+		}
+		if !result.IsSuccess() {
+			return checker.ClassAssertion
+		}
+	case ColumnError:
+		if !result.IsSuccess() {
+			return FailureReason(result)
+		}
+	case ColumnFinalURL:
+		return result.FinalURL
+	case ColumnContentLength:
+		if result.StatusCode != 0 && result.ContentLength >= 0 {
+			return strconv.FormatInt(result.ContentLength, 10)
+		}
+	case ColumnTimestamp:
+		if !result.StartedAt.IsZero() {
+			return result.StartedAt.Format(time.RFC3339)
+		}
+	case ColumnReferrer:
+		return result.Referrer
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func TestCSVWriter(t *testing.T) {
+	// Время в таблице - начало проверки, а не момент записи строки
+	started := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	results := sampleResults()
+	results[0].StartedAt = started
+	results[1].StartedAt = started.Add(5 * time.Second)
+	results[0].FinalURL = "http://ok.com/home"
+	results[0].ContentLength = 512
+	results = append(results, types.Result{
+		URL:           "http://missing.com/a,b",
+		StatusCode:    404,
+		Duration:      20 * time.Millisecond,
+		ContentLength: -1,
+		Referrer:      "http://ok.com/home",
+		Assertions:    []types.Assertion{{Name: "status", Message: `unexpected status code 404, expected "2xx"`}},
+	})
+
+	testCases := []struct {
+		name     string
+		comma    rune
+		columns  []string
+		expected string
+	}{
+		{
+			name:    "csv with default columns",
+			comma:   ',',
+			columns: DefaultColumns,
+			expected: "url,status,duration_ms,error_class,final_url,content_length,timestamp\n" +
+				"http://ok.com,200,1.5,,http://ok.com/home,512,2026-10-17T12:00:00Z\n" +
+				"http://down.com,,5000,timeout,,,2026-10-17T12:00:05Z\n" +
+				`"http://missing.com/a,b",404,20,assertion,,,` + "\n",
+		},
+		{
+			name:    "tsv with quoted error",
+			comma:   '\t',
+			columns: []string{ColumnURL, ColumnSuccess, ColumnError},
+			expected: "url\tsuccess\terror\n" +
+				"http://ok.com\ttrue\t\n" +
+				"http://down.com\tfalse\ttimeout for http://down.com\n" +
+				"http://missing.com/a,b\tfalse\t" + `"404, 20ms: unexpected status code 404, expected ""2xx"""` + "\n",
+		},
+		{
+			name:    "referrer of a broken link",
+			comma:   ',',
+			columns: []string{ColumnURL, ColumnStatus, ColumnReferrer},
+			expected: "url,status,referrer\n" +
+				"http://ok.com,200,\n" +
+				"http://down.com,,\n" +
+				`"http://missing.com/a,b",404,http://ok.com/home` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		w := NewCSVWriter(&buf, tc.comma, tc.columns)
+
+		for i, r := range results {
+			w.WriteProgress(i+1, len(results), r)
+		}
+		w.WriteSummary(Summary{})
+
+		if w.Err() != nil {
+			t.Fatalf("%s: expected no error, got %v", tc.name, w.Err())
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tc.name, tc.expected, buf.String())
+		}
+	}
+}
+
+func TestCSVWriter_EmptyTable(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, ',', []string{ColumnURL, ColumnStatus})
+	w.WriteSummary(Summary{})
+
+	if buf.String() != "url,status\n" {
+		t.Errorf("Expected header only, got %q", buf.String())
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+		valid    bool
+	}{
+		{"", DefaultColumns, true},
+		{"url, status", []string{ColumnURL, ColumnStatus}, true},
+		{"url,size", nil, false},
+	}
+
+	for _, tc := range testCases {
+		columns, err := ParseColumns(tc.input)
+		if (err == nil) != tc.valid {
+			t.Errorf("%q: expected valid %v, got %v", tc.input, tc.valid, err)
+			continue
+		}
+		if len(columns) != len(tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.input, tc.expected, columns)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// JSONResult - представление types.Result в структурированном выводе
+type JSONResult struct {
+	Type       string   `json:"type,omitempty"`
+	URL        string   `json:"url"`
+	Method     string   `json:"method,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	StatusCode int      `json:"status"`
+	DurationMs float64  `json:"duration_ms"`
+	Success    bool     `json:"success"`
+	ErrorClass string   `json:"error_class,omitempty"`
+	Error      string   `json:"error,omitempty"`
+
+	// FailedAssertions - невыполненные ожидания к ответу
+	FailedAssertions []JSONAssertion `json:"failed_assertions,omitempty"`
+
+	Timing   *JSONTiming `json:"timing,omitempty"`
+	TLS      *JSONTLS    `json:"tls,omitempty"`
+	Warnings []string    `json:"warnings,omitempty"`
+
+	// FinalURL заполняется, только если он отличается от URL
+	FinalURL  string         `json:"final_url,omitempty"`
+	Redirects []JSONRedirect `json:"redirects,omitempty"`
+
+	// Referrer - страница со ссылкой на URL в режиме crawl
+	Referrer string `json:"referrer,omitempty"`
+
+	// Attempts заполняется, только если были повторы
+	Attempts []JSONAttempt `json:"attempts,omitempty"`
+}
+
+// JSONTiming - длительность фаз запроса
+type JSONTiming struct {
+	DNSMs      float64 `json:"dns_ms"`
+	ConnectMs  float64 `json:"connect_ms"`
+	TLSMs      float64 `json:"tls_ms"`
+	TTFBMs     float64 `json:"ttfb_ms"`
+	TransferMs float64 `json:"transfer_ms"`
+	ConnReused bool    `json:"conn_reused"`
+}
+
+// JSONTLS - параметры TLS-соединения
+type JSONTLS struct {
+	Version      string            `json:"version"`
+	CipherSuite  string            `json:"cipher_suite"`
+	Certificates []JSONCertificate `json:"certificates"`
+}
+
+// JSONCertificate - сертификат из цепочки сервера
+type JSONCertificate struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	SANs     []string  `json:"sans,omitempty"`
+	NotAfter time.Time `json:"not_after"`
+}
+
+// JSONRedirect - шаг цепочки редиректов
+type JSONRedirect struct {
+	URL        string  `json:"url"`
+	StatusCode int     `json:"status"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// JSONAttempt - исход одной попытки запроса
+type JSONAttempt struct {
+	StatusCode int     `json:"status"`
+	DurationMs float64 `json:"duration_ms"`
+	ErrorClass string  `json:"error_class,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// JSONAssertion - невыполненное ожидание к ответу
+type JSONAssertion struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// JSONSummary - представление Summary в структурированном выводе
+type JSONSummary struct {
+	Type       string  `json:"type,omitempty"`
+	Total      int     `json:"total"`
+	Success    int     `json:"success"`
+	Failed     int     `json:"failed"`
+	DurationMs float64 `json:"duration_ms"`
+	Rate       float64 `json:"rate"`
+
+	AssertionFailures int `json:"assertion_failures"`
+	Warnings          int `json:"warnings"`
+
+	Stopped bool `json:"stopped,omitempty"`
+	Skipped int  `json:"skipped,omitempty"`
+
+	Latency      *JSONLatency   `json:"latency,omitempty"`
+	StatusCodes  map[int]int    `json:"status_codes,omitempty"`
+	ErrorClasses map[string]int `json:"error_classes,omitempty"`
+
+	Baseline *JSONBaseline `json:"baseline,omitempty"`
+}
+
+// JSONBaseline - отличия от базового запуска
+type JSONBaseline struct {
+	Path        string       `json:"path"`
+	Regressions int          `json:"regressions"`
+	Changes     []JSONChange `json:"changes"`
+}
+
+// JSONChange - отличие результата URL от базового запуска
+type JSONChange struct {
+	URL        string `json:"url"`
+	Kind       string `json:"kind"`
+	Message    string `json:"message"`
+	Regression bool   `json:"regression"`
+}
+
+// JSONLatency - распределение задержек проверок, получивших ответ
+type JSONLatency struct {
+	MinMs  float64 `json:"min_ms"`
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  float64 `json:"p50_ms"`
+	P90Ms  float64 `json:"p90_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+	MaxMs  float64 `json:"max_ms"`
+
+	Histogram []JSONHistogramBucket `json:"histogram"`
+}
+
+// JSONHistogramBucket - корзина гистограммы; у последней le_ms не задан
+type JSONHistogramBucket struct {
+	LeMs  float64 `json:"le_ms,omitempty"`
+	Count int     `json:"count"`
+}
+
+// JSONReport - документ, который выводится в формате json
+type JSONReport struct {
+	Results []JSONResult `json:"results"`
+	Summary JSONSummary  `json:"summary"`
+}
+
+// JSONWriter выводит результаты в формате json (один документ JSONReport)
+// или jsonl (объект на строку, итог последней строкой). Документ json
+// пишется по мере поступления результатов, поэтому они не копятся в памяти.
+type JSONWriter struct {
+	w     io.Writer
+	enc   *json.Encoder
+	lines bool
+	// count - число результатов, уже записанных в документ json
+	count int
+	err   error
+}
+
+func NewJSONWriter(w io.Writer, lines bool) *JSONWriter {
+	return &JSONWriter{w: w, enc: json.NewEncoder(w), lines: lines}
+}
+
+func (w *JSONWriter) WriteProgress(current, total int, result types.Result) {
+	jr := NewJSONResult(result)
+	if w.lines {
+		jr.Type = "result"
+		w.encode(jr)
+		return
+	}
+
+	// Отступы совпадают с json.MarshalIndent для всего документа
+	prefix := ",\n    "
+	if w.count == 0 {
+		prefix = "{\n  \"results\": [\n    "
+	}
+	w.count++
+	w.writeIndented(prefix, jr, "    ")
+}
+
+func (w *JSONWriter) WriteSummary(summary Summary) {
+	js := NewJSONSummary(summary)
+	if w.lines {
+		js.Type = "summary"
+		w.encode(js)
+		return
+	}
+
+	prefix := "\n  ],\n  \"summary\": "
+	if w.count == 0 {
+		prefix = "{\n  \"results\": [],\n  \"summary\": "
+	}
+	w.writeIndented(prefix, js, "  ")
+	w.write("\n}\n")
+}
+
+// Err возвращает первую ошибку записи
+func (w *JSONWriter) Err() error {
+	return w.err
+}
+
+func (w *JSONWriter) encode(v any) {
+	if w.err != nil {
+		return
+	}
+	w.err = w.enc.Encode(v)
+}
+
+// writeIndented записывает prefix и v с отступом indent у вложенных строк
+func (w *JSONWriter) writeIndented(prefix string, v any, indent string) {
+	if w.err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(v, indent, "  ")
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.write(prefix + string(data))
+}
+
+func (w *JSONWriter) write(s string) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = io.WriteString(w.w, s)
+}
+
+func NewJSONResult(result types.Result) JSONResult {
+	jr := JSONResult{
+		URL:        result.URL,
+		Method:     result.Method,
+		Tags:       result.Tags,
+		Referrer:   result.Referrer,
+		StatusCode: result.StatusCode,
+		DurationMs: durationMs(result.Duration),
+		Success:    result.IsSuccess(),
+	}
+	if result.Error != nil {
+		jr.ErrorClass = checker.ErrorClass(result.Error)
+		jr.Error = result.Error.Error()
+	}
+	if t := result.Timing; t != (types.Timing{}) {
+		jr.Timing = &JSONTiming{
+			DNSMs:      durationMs(t.DNS),
+			ConnectMs:  durationMs(t.Connect),
+			TLSMs:      durationMs(t.TLS),
+			TTFBMs:     durationMs(t.TTFB),
+			TransferMs: durationMs(t.Transfer),
+			ConnReused: t.ConnReused,
+		}
+	}
+	if result.TLS != nil {
+		jr.TLS = &JSONTLS{Version: result.TLS.Version, CipherSuite: result.TLS.CipherSuite}
+		for _, c := range result.TLS.Certificates {
+			jr.TLS.Certificates = append(jr.TLS.Certificates, JSONCertificate{
+				Subject:  c.Subject,
+				Issuer:   c.Issuer,
+				SANs:     c.SANs,
+				NotAfter: c.NotAfter,
+			})
+		}
+	}
+	jr.Warnings = result.Warnings
+	if result.FinalURL != result.URL {
+		jr.FinalURL = result.FinalURL
+	}
+	for _, r := range result.Redirects {
+		jr.Redirects = append(jr.Redirects, JSONRedirect{
+			URL:        r.URL,
+			StatusCode: r.StatusCode,
+			DurationMs: durationMs(r.Duration),
+		})
+	}
+	for _, a := range result.FailedAssertions() {
+		jr.FailedAssertions = append(jr.FailedAssertions, JSONAssertion{Name: a.Name, Message: a.Message})
+	}
+	if len(result.Attempts) > 1 {
+		for _, a := range result.Attempts {
+			ja := JSONAttempt{StatusCode: a.StatusCode, DurationMs: durationMs(a.Duration)}
+			if a.Error != nil {
+				ja.ErrorClass = checker.ErrorClass(a.Error)
+				ja.Error = a.Error.Error()
+			}
+			jr.Attempts = append(jr.Attempts, ja)
+		}
+	}
+	return jr
+}
+
+func NewJSONSummary(summary Summary) JSONSummary {
+	js := JSONSummary{
+		Total:      summary.Total,
+		Success:    summary.Success,
+		Failed:     summary.Failed,
+		DurationMs: durationMs(summary.Duration),
+		Rate:       math.Round(summary.Rate()*100) / 100,
+
+		AssertionFailures: summary.AssertionFailures,
+		Warnings:          summary.Warnings,
+		Stopped:           summary.Stopped,
+		Skipped:           summary.Skipped,
+		StatusCodes:       summary.StatusCodes,
+		ErrorClasses:      summary.ErrorClasses,
+	}
+
+	if l := summary.Latency; l.Count > 0 {
+		js.Latency = &JSONLatency{
+			MinMs:  durationMs(l.Min),
+			MeanMs: durationMs(l.Mean()),
+			P50Ms:  durationMs(l.Percentile(50)),
+			P90Ms:  durationMs(l.Percentile(90)),
+			P95Ms:  durationMs(l.Percentile(95)),
+			P99Ms:  durationMs(l.Percentile(99)),
+			MaxMs:  durationMs(l.Max),
+		}
+		for _, b := range l.Histogram() {
+			js.Latency.Histogram = append(js.Latency.Histogram,
+				JSONHistogramBucket{LeMs: durationMs(b.UpperBound), Count: b.Count})
+		}
+	}
+
+	if summary.Baseline != "" {
+		js.Baseline = &JSONBaseline{
+			Path:        summary.Baseline,
+			Regressions: summary.Regressions(),
+			Changes:     []JSONChange{},
+		}
+		for _, c := range summary.Changes {
+			js.Baseline.Changes = append(js.Baseline.Changes, JSONChange{
+				URL:        c.URL,
+				Kind:       c.Kind,
+				Message:    c.Message,
+				Regression: c.Regression,
+			})
+		}
+	}
+
+	return js
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,191 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func sampleResults() []types.Result {
+	return []types.Result{
+		{URL: "http://ok.com", StatusCode: 200, Duration: 1500 * time.Microsecond},
+		{URL: "http://down.com", Duration: 5 * time.Second, Error: checker.ErrTimeout{URL: "http://down.com"}},
+	}
+}
+
+func TestJSONWriter_Document(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONWriter(&buf, false)
+
+	for i, r := range sampleResults() {
+		w.WriteProgress(i+1, 2, r)
+	}
+	w.WriteSummary(Summary{Total: 2, Success: 1, Failed: 1, Duration: 5 * time.Second})
+
+	if w.Err() != nil {
+		t.Fatalf("Expected no error, got %v", w.Err())
+	}
+
+	var report JSONReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, buf.String())
+	}
+
+	if len(report.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(report.Results))
+	}
+	if report.Results[0].DurationMs != 1.5 || !report.Results[0].Success {
+		t.Errorf("Unexpected first result: %+v", report.Results[0])
+	}
+	if report.Results[1].ErrorClass != checker.ClassTimeout || report.Results[1].Error == "" {
+		t.Errorf("Unexpected second result: %+v", report.Results[1])
+	}
+	if report.Summary.Total != 2 || report.Summary.DurationMs != 5000 {
+		t.Errorf("Unexpected summary: %+v", report.Summary)
+	}
+}
+
+func TestJSONWriter_DocumentStreaming(t *testing.T) {
+	summary := Summary{Total: 2, Success: 1, Failed: 1}
+
+	for _, results := range [][]types.Result{sampleResults(), nil} {
+		var buf bytes.Buffer
+		w := NewJSONWriter(&buf, false)
+
+		report := JSONReport{Results: []JSONResult{}, Summary: NewJSONSummary(summary)}
+		for i, r := range results {
+			w.WriteProgress(i+1, len(results), r)
+			report.Results = append(report.Results, NewJSONResult(r))
+
+			// Результат выводится сразу, а не копится до итога
+			if buf.Len() == 0 {
+				t.Fatal("Expected result to be written before the summary")
+			}
+		}
+		w.WriteSummary(summary)
+
+		if w.Err() != nil {
+			t.Fatalf("Expected no error, got %v", w.Err())
+		}
+
+		// Документ совпадает с тем, что дал бы json.MarshalIndent целиком
+		expected, _ := json.MarshalIndent(report, "", "  ")
+		if got := buf.String(); got != string(expected)+"\n" {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+	}
+}
+
+func TestJSONWriter_Lines(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONWriter(&buf, true)
+
+	for i, r := range sampleResults() {
+		w.WriteProgress(i+1, 2, r)
+	}
+	w.WriteSummary(Summary{Total: 2, Success: 1, Failed: 1})
+
+	var kinds []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Expected valid JSON line, got %v: %s", err, scanner.Text())
+		}
+		kinds = append(kinds, line.Type)
+	}
+
+	expected := []string{"result", "result", "summary"}
+	if len(kinds) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d", len(expected), len(kinds))
+	}
+	for i := range expected {
+		if kinds[i] != expected[i] {
+			t.Errorf("Line %d: expected type %q, got %q", i, expected[i], kinds[i])
+		}
+	}
+}
+
+func TestNewJSONResult_Redirects(t *testing.T) {
+	result := types.Result{
+		URL:        "http://example.com",
+		StatusCode: 200,
+		FinalURL:   "https://example.com/",
+		Redirects: []types.Redirect{
+			{URL: "http://example.com", StatusCode: 301, Duration: 2 * time.Millisecond},
+		},
+	}
+
+	jr := NewJSONResult(result)
+
+	if jr.FinalURL != "https://example.com/" {
+		t.Errorf("Expected final URL, got %q", jr.FinalURL)
+	}
+	if len(jr.Redirects) != 1 || jr.Redirects[0].StatusCode != 301 || jr.Redirects[0].DurationMs != 2 {
+		t.Errorf("Unexpected redirects: %+v", jr.Redirects)
+	}
+
+	plain := NewJSONResult(types.Result{URL: "http://ok.com", FinalURL: "http://ok.com", StatusCode: 200})
+	if plain.FinalURL != "" || plain.Redirects != nil {
+		t.Errorf("Expected no redirect fields without redirects, got %+v", plain)
+	}
+}
+
+func TestNewJSONResult_TLS(t *testing.T) {
+	notAfter := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	result := types.Result{
+		URL:        "https://example.com",
+		StatusCode: 200,
+		TLS: &types.TLSInfo{
+			Version:      "TLS 1.3",
+			CipherSuite:  "TLS_AES_128_GCM_SHA256",
+			Certificates: []types.Certificate{{Subject: "CN=example.com", NotAfter: notAfter}},
+		},
+		Warnings: []string{"leaf certificate CN=example.com expires in 14 days (2026-11-01)"},
+	}
+
+	jr := NewJSONResult(result)
+
+	if jr.TLS == nil || jr.TLS.Version != "TLS 1.3" || len(jr.TLS.Certificates) != 1 {
+		t.Fatalf("Unexpected TLS: %+v", jr.TLS)
+	}
+	if !jr.TLS.Certificates[0].NotAfter.Equal(notAfter) {
+		t.Errorf("Expected not_after %v, got %v", notAfter, jr.TLS.Certificates[0].NotAfter)
+	}
+	if len(jr.Warnings) != 1 || !jr.Success {
+		t.Errorf("Expected successful result with a warning, got %+v", jr)
+	}
+
+	var summary Summary
+	summary.Add(result)
+	summary.Add(types.Result{URL: "http://ok.com", StatusCode: 200})
+	if summary.Warnings != 1 || summary.Success != 2 {
+		t.Errorf("Expected 2 successes and 1 warning, got %+v", summary)
+	}
+}
+
+func TestNewJSONSummary_Baseline(t *testing.T) {
+	js := NewJSONSummary(Summary{Total: 1})
+	if js.Baseline != nil {
+		t.Errorf("Expected no baseline without comparison, got %+v", js.Baseline)
+	}
+
+	js = NewJSONSummary(Summary{
+		Total:    2,
+		Baseline: "before.json",
+		Changes: []types.Change{
+			{URL: "http://a.com", Kind: types.ChangeFailing, Message: "timeout", Regression: true},
+			{URL: "http://b.com", Kind: types.ChangeRecovered, Message: "200, 5ms"},
+		},
+	})
+	if js.Baseline == nil || js.Baseline.Regressions != 1 || len(js.Baseline.Changes) != 2 {
+		t.Errorf("Unexpected baseline: %+v", js.Baseline)
+	}
+}
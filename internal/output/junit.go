@@ -0,0 +1,143 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// JUnitWriter собирает результаты и при выводе итогов пишет отчет в формате
+// JUnit XML, где каждый URL - отдельный testcase
+type JUnitWriter struct {
+	w     io.Writer
+	cases []junitTestCase
+	start time.Time
+	err   error
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func NewJUnitWriter(w io.Writer) *JUnitWriter {
+	return &JUnitWriter{w: w, start: time.Now()}
+}
+
+func (w *JUnitWriter) WriteProgress(current, total int, result types.Result) {
+	tc := junitTestCase{
+		Name:      result.URL,
+		Classname: "urlcheck",
+		Time:      junitSeconds(result.Duration),
+	}
+
+	if !result.IsSuccess() {
+		tc.Failure = newJUnitFailure(result)
+	}
+
+	// В JUnit нет предупреждений и фаз запроса, поэтому они попадают в вывод теста
+	var out []string
+	if result.Referrer != "" {
+		out = append(out, "linked from: "+result.Referrer)
+	}
+	for _, warning := range result.Warnings {
+		out = append(out, "warning: "+warning)
+	}
+	if result.Timing != (types.Timing{}) {
+		out = append(out, "timing: "+FormatTiming(result.Timing))
+	}
+	tc.SystemOut = strings.Join(out, "\n")
+
+	w.cases = append(w.cases, tc)
+}
+
+func (w *JUnitWriter) WriteSummary(summary Summary) {
+	suite := junitTestSuite{
+		Name:      "urlcheck",
+		Tests:     len(w.cases),
+		Failures:  summary.Failed,
+		Time:      junitSeconds(summary.Duration),
+		Timestamp: w.start.UTC().Format(time.RFC3339),
+		Cases:     w.cases,
+	}
+
+	if _, err := io.WriteString(w.w, xml.Header); err != nil {
+		w.err = err
+		return
+	}
+
+	enc := xml.NewEncoder(w.w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		w.err = err
+		return
+	}
+
+	_, w.err = io.WriteString(w.w, "\n")
+}
+
+// Err возвращает ошибку записи отчета
+func (w *JUnitWriter) Err() error {
+	return w.err
+}
+
+func newJUnitFailure(result types.Result) *junitFailure {
+	if result.Error != nil {
+		return &junitFailure{
+			Message: result.Error.Error(),
+			Type:    checker.ErrorClass(result.Error),
+			Text:    fmt.Sprintf("%s failed after %v: %v", result.URL, result.Duration, result.Error),
+		}
+	}
+
+	if failed := result.FailedAssertions(); len(failed) > 0 {
+		messages := make([]string, len(failed))
+		for i, a := range failed {
+			messages[i] = a.Message
+		}
+		return &junitFailure{
+			Message: strings.Join(messages, "; "),
+			Type:    checker.ClassAssertion,
+			Text:    fmt.Sprintf("%s responded with %d in %v:\n%s", result.URL, result.StatusCode, result.Duration, strings.Join(messages, "\n")),
+		}
+	}
+
+	return &junitFailure{
+		Message: fmt.Sprintf("unexpected status code %d", result.StatusCode),
+		Type:    "status",
+		Text:    fmt.Sprintf("%s responded with %d in %v", result.URL, result.StatusCode, result.Duration),
+	}
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
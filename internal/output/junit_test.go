@@ -0,0 +1,59 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+)
+
+func TestJUnitWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJUnitWriter(&buf)
+
+	results := sampleResults()
+	results = append(results, results[0])
+	results[2].URL = "http://missing.com"
+	results[2].StatusCode = 404
+
+	for i, r := range results {
+		w.WriteProgress(i+1, len(results), r)
+	}
+	w.WriteSummary(Summary{Total: 3, Success: 1, Failed: 2, Duration: 5 * time.Second})
+
+	if w.Err() != nil {
+		t.Fatalf("Expected no error, got %v", w.Err())
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Expected valid XML, got %v: %s", err, buf.String())
+	}
+
+	if len(report.Suites) != 1 {
+		t.Fatalf("Expected 1 testsuite, got %d", len(report.Suites))
+	}
+	suite := report.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 2 || suite.Time != "5.000" {
+		t.Errorf("Unexpected suite attributes: %+v", suite)
+	}
+
+	if suite.Cases[0].Failure != nil {
+		t.Errorf("Expected first case to pass, got %+v", suite.Cases[0].Failure)
+	}
+	if suite.Cases[0].Time != "0.002" {
+		t.Errorf("Expected time 0.002, got %s", suite.Cases[0].Time)
+	}
+
+	timeout := suite.Cases[1].Failure
+	if timeout == nil || timeout.Type != checker.ClassTimeout || timeout.Message != "timeout for http://down.com" {
+		t.Errorf("Unexpected timeout failure: %+v", timeout)
+	}
+
+	status := suite.Cases[2].Failure
+	if status == nil || status.Type != "status" {
+		t.Errorf("Unexpected status failure: %+v", status)
+	}
+}
@@ -0,0 +1,130 @@
+package output
+
+import (
+	"math"
+	"time"
+)
+
+// Точные корзины растут в latencyGrowth раз, начиная с 1µs, поэтому
+// перцентили определяются с погрешностью не больше 1%. Задержки
+// больше часа попадают в последнюю корзину.
+const (
+	latencyGrowth  = 1.01
+	latencyBuckets = 2200
+)
+
+// histogramBounds - верхние границы корзин гистограммы для вывода
+var histogramBounds = []time.Duration{
+	time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second,
+}
+
+// LatencyStats накапливает распределение задержек. Память не зависит
+// от числа результатов: хранятся только счетчики корзин.
+type LatencyStats struct {
+	Count int
+	Min   time.Duration
+	Max   time.Duration
+
+	sum     time.Duration
+	buckets []int
+	// histogram - счетчики по histogramBounds, последний - больше всех границ
+	histogram []int
+}
+
+// HistogramBucket - число задержек не больше UpperBound; у последней
+// корзины UpperBound равен 0 и она собирает все, что больше
+type HistogramBucket struct {
+	UpperBound time.Duration
+	Count      int
+}
+
+func (l *LatencyStats) Add(d time.Duration) {
+	if l.buckets == nil {
+		l.buckets = make([]int, latencyBuckets)
+		l.histogram = make([]int, len(histogramBounds)+1)
+	}
+
+	if l.Count == 0 || d < l.Min {
+		l.Min = d
+	}
+	if d > l.Max {
+		l.Max = d
+	}
+	l.Count++
+	l.sum += d
+	l.buckets[latencyBucket(d)]++
+
+	i := 0
+	for i < len(histogramBounds) && d > histogramBounds[i] {
+		i++
+	}
+	l.histogram[i]++
+}
+
+func (l LatencyStats) Mean() time.Duration {
+	if l.Count == 0 {
+		return 0
+	}
+	return l.sum / time.Duration(l.Count)
+}
+
+// Percentile возвращает задержку, не превышенную p процентами результатов
+func (l LatencyStats) Percentile(p float64) time.Duration {
+	if l.Count == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(l.Count)))
+	rank = max(rank, 1)
+
+	seen := 0
+	for i, n := range l.buckets {
+		seen += n
+		if seen >= rank {
+			// Верхняя граница корзины не должна выходить за наблюдаемые значения
+			return min(max(bucketUpperBound(i), l.Min), l.Max)
+		}
+	}
+	return l.Max
+}
+
+// Histogram возвращает грубую гистограмму от первой до последней непустой корзины
+func (l LatencyStats) Histogram() []HistogramBucket {
+	first, last := -1, -1
+	for i, n := range l.histogram {
+		if n > 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+
+	buckets := make([]HistogramBucket, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		b := HistogramBucket{Count: l.histogram[i]}
+		if i < len(histogramBounds) {
+			b.UpperBound = histogramBounds[i]
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets
+}
+
+func latencyBucket(d time.Duration) int {
+	if d <= time.Microsecond {
+		return 0
+	}
+	i := int(math.Ceil(math.Log(float64(d)/float64(time.Microsecond)) / math.Log(latencyGrowth)))
+	return min(i, latencyBuckets-1)
+}
+
+func bucketUpperBound(i int) time.Duration {
+	return time.Duration(float64(time.Microsecond) * math.Pow(latencyGrowth, float64(i)))
+}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatencyStats_Percentiles(t *testing.T) {
+	var l LatencyStats
+	for i := 1; i <= 1000; i++ {
+		l.Add(time.Duration(i) * time.Millisecond)
+	}
+
+	if l.Count != 1000 || l.Min != time.Millisecond || l.Max != time.Second {
+		t.Fatalf("Unexpected stats: count %d, min %v, max %v", l.Count, l.Min, l.Max)
+	}
+	if l.Mean() != 500500*time.Microsecond {
+		t.Errorf("Expected mean 500.5ms, got %v", l.Mean())
+	}
+
+	testCases := []struct {
+		p        float64
+		expected time.Duration
+	}{
+		{0, time.Millisecond},
+		{50, 500 * time.Millisecond},
+		{90, 900 * time.Millisecond},
+		{99, 990 * time.Millisecond},
+		{100, time.Second},
+	}
+
+	for _, tc := range testCases {
+		got := l.Percentile(tc.p)
+		if diff := math.Abs(float64(got-tc.expected)) / float64(tc.expected); diff > 0.01 {
+			t.Errorf("p%v: expected %v within 1%%, got %v", tc.p, tc.expected, got)
+		}
+	}
+}
+
+func TestLatencyStats_Empty(t *testing.T) {
+	var l LatencyStats
+	if l.Mean() != 0 || l.Percentile(99) != 0 || l.Histogram() != nil {
+		t.Error("Expected zero values for empty stats")
+	}
+}
+
+func TestLatencyStats_Histogram(t *testing.T) {
+	var l LatencyStats
+	for _, d := range []time.Duration{
+		3 * time.Millisecond, 4 * time.Millisecond, 15 * time.Millisecond, 30 * time.Second,
+	} {
+		l.Add(d)
+	}
+
+	h := l.Histogram()
+
+	// От ≤5ms до корзины больше 10s без пропусков
+	if len(h) != 12 {
+		t.Fatalf("Expected 12 buckets, got %d: %+v", len(h), h)
+	}
+	if h[0].UpperBound != 5*time.Millisecond || h[0].Count != 2 {
+		t.Errorf("Unexpected first bucket: %+v", h[0])
+	}
+	if h[2].UpperBound != 20*time.Millisecond || h[2].Count != 1 {
+		t.Errorf("Unexpected third bucket: %+v", h[2])
+	}
+	if last := h[len(h)-1]; last.UpperBound != 0 || last.Count != 1 {
+		t.Errorf("Unexpected overflow bucket: %+v", last)
+	}
+
+	text := FormatHistogram(h, 10)
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if len(lines) != len(h) {
+		t.Fatalf("Expected %d lines, got %q", len(h), text)
+	}
+	if !strings.Contains(lines[0], strings.Repeat("█", 10)+" 2") {
+		t.Errorf("Expected full bar for the largest bucket, got %q", lines[0])
+	}
+	if !strings.Contains(lines[len(lines)-1], ">10s █████ 1") {
+		t.Errorf("Expected overflow bucket line, got %q", lines[len(lines)-1])
+	}
+}
@@ -2,14 +2,35 @@ package output
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/nashabanov/urlcheck/internal/checker"
 	"github.com/nashabanov/urlcheck/internal/types"
 )
 
+// Поддерживаемые форматы вывода
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+	FormatTSV   = "tsv"
+)
+
+// ResultWriter выводит результаты проверок по мере поступления и итоговую статистику
+type ResultWriter interface {
+	WriteProgress(current, total int, result types.Result)
+	WriteSummary(summary Summary)
+}
+
 type Config struct {
 	ColorOutput bool
+	// Verbose добавляет к каждому результату разбивку времени по фазам
+	Verbose bool
 }
 
 type Writer struct {
@@ -31,25 +52,75 @@ func (w *Writer) WriteProgress(current, total int, result types.Result) {
 	var status string
 	if result.Error != nil {
 		status = w.colorize("✗", ColorRed)
-	} else if result.StatusCode >= 200 && result.StatusCode < 300 {
-		status = w.colorize("✓", ColorGreen)
-	} else {
+	} else if !result.IsSuccess() {
 		status = w.colorize("!", ColorYellow)
+	} else if len(result.Warnings) > 0 {
+		status = w.colorize("⚠", ColorYellow)
+	} else {
+		status = w.colorize("✓", ColorGreen)
 	}
 
 	var details string
 	if result.Error != nil {
-		details = fmt.Sprintf("(%s)", result.Error)
+		details = result.Error.Error()
 	} else {
-		details = fmt.Sprintf("(%d, %v)", result.StatusCode, result.Duration)
+		details = fmt.Sprintf("%d, %v", result.StatusCode, result.Duration)
+	}
+	if n := len(result.Redirects); n > 0 {
+		details += fmt.Sprintf(", %d %s -> %s", n, plural(n, "redirect", "redirects"), result.FinalURL)
 	}
+	if len(result.Attempts) > 1 {
+		details += fmt.Sprintf(", %d attempts", len(result.Attempts))
+	}
+	details = "(" + details + ")"
 
-	totalDigits := len(fmt.Sprintf("%d", total))
-	progress := fmt.Sprintf("[%*d/%d]", totalDigits, current, total)
+	for _, a := range result.FailedAssertions() {
+		details += "\n    " + w.colorize("- "+a.Message, ColorYellow)
+	}
+	for _, warning := range result.Warnings {
+		details += "\n    " + w.colorize("- warning: "+warning, ColorYellow)
+	}
+	// Для битой ссылки важно, где ее исправлять
+	if result.Referrer != "" && !result.IsSuccess() {
+		details += "\n    linked from " + result.Referrer
+	}
+	if w.config.Verbose && result.Timing != (types.Timing{}) {
+		details += "\n    " + FormatTiming(result.Timing)
+	}
+
+	var progress string
+	if total > 0 {
+		totalDigits := len(fmt.Sprintf("%d", total))
+		progress = fmt.Sprintf("[%*d/%d]", totalDigits, current, total)
+	} else {
+		// Общее число неизвестно при потоковом чтении
+		progress = fmt.Sprintf("[%d checked]", current)
+	}
 
 	fmt.Printf("%s %s %s %s\n", progress, status, result.URL, details)
 }
 
+// FormatTiming возвращает разбивку времени запроса по фазам в одну строку
+func FormatTiming(t types.Timing) string {
+	phases := fmt.Sprintf("ttfb %v, transfer %v", round(t.TTFB), round(t.Transfer))
+	if t.ConnReused {
+		return "reused connection, " + phases
+	}
+	return fmt.Sprintf("dns %v, connect %v, tls %v, %s",
+		round(t.DNS), round(t.Connect), round(t.TLS), phases)
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
 func (w *Writer) colorize(text, color string) string {
 	if !w.config.ColorOutput || !isColorSupported() {
 		return text
@@ -70,6 +141,86 @@ type Summary struct {
 	Success  int
 	Failed   int
 	Duration time.Duration
+
+	// AssertionFailures - общее число невыполненных ожиданий
+	AssertionFailures int
+
+	// Warnings - число проверок с предупреждениями, успешных и нет
+	Warnings int
+
+	// FailedTags - число неуспешных проверок по меткам целей
+	FailedTags map[string]int
+
+	// Latency и StatusCodes учитывают только проверки, получившие ответ,
+	// ErrorClasses - только завершившиеся ошибкой
+	Latency      LatencyStats
+	StatusCodes  map[int]int
+	ErrorClasses map[string]int
+
+	// Baseline - файл базового запуска, Changes - отличия от него;
+	// пустой Baseline означает, что сравнение не выполнялось
+	Baseline string
+	Changes  []types.Change
+
+	// Stopped - проверка остановлена после первого отказа (-fail-fast);
+	// Skipped - число целей, проверка которых не начиналась, если общее
+	// число известно
+	Stopped bool
+	Skipped int
+}
+
+// Add учитывает результат проверки в статистике
+func (s *Summary) Add(result types.Result) {
+	s.Total++
+	// Успешны проверки без ошибок, выполнившие все ожидания
+	if result.IsSuccess() {
+		s.Success++
+	} else {
+		s.Failed++
+		for _, tag := range result.Tags {
+			if s.FailedTags == nil {
+				s.FailedTags = map[string]int{}
+			}
+			s.FailedTags[tag]++
+		}
+	}
+	s.AssertionFailures += len(result.FailedAssertions())
+	if len(result.Warnings) > 0 {
+		s.Warnings++
+	}
+
+	if result.Error != nil {
+		if s.ErrorClasses == nil {
+			s.ErrorClasses = map[string]int{}
+		}
+		s.ErrorClasses[checker.ErrorClass(result.Error)]++
+		return
+	}
+
+	s.Latency.Add(result.Duration)
+	if s.StatusCodes == nil {
+		s.StatusCodes = map[int]int{}
+	}
+	s.StatusCodes[result.StatusCode]++
+}
+
+// Regressions возвращает число изменений к худшему относительно базового запуска
+func (s Summary) Regressions() int {
+	n := 0
+	for _, c := range s.Changes {
+		if c.Regression {
+			n++
+		}
+	}
+	return n
+}
+
+// Rate возвращает фактическую частоту проверок в секунду
+func (s Summary) Rate() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Total) / s.Duration.Seconds()
 }
 
 func (w *Writer) WriteSummary(summary Summary) {
@@ -81,5 +232,104 @@ func (w *Writer) WriteSummary(summary Summary) {
 	failedText := w.colorize(fmt.Sprintf("%d failed", summary.Failed), ColorRed)
 
 	fmt.Printf("Summary: %s, %s, %.1f%% success rate\n", successText, failedText, successRate)
-	fmt.Printf("Total: %d URLs checked in %v\n", summary.Total, summary.Duration.Round(time.Millisecond))
+	if summary.AssertionFailures > 0 {
+		fmt.Printf("Assertions: %s\n",
+			w.colorize(fmt.Sprintf("%d failed", summary.AssertionFailures), ColorYellow))
+	}
+	if summary.Warnings > 0 {
+		fmt.Printf("Warnings: %s\n",
+			w.colorize(fmt.Sprintf("%d URLs", summary.Warnings), ColorYellow))
+	}
+	fmt.Printf("Total: %d URLs checked in %v (%.1f req/s)\n",
+		summary.Total, summary.Duration.Round(time.Millisecond), summary.Rate())
+	if summary.Stopped {
+		stopped := "after first failure"
+		if summary.Skipped > 0 {
+			stopped += fmt.Sprintf(", %d URLs skipped", summary.Skipped)
+		}
+		fmt.Printf("Stopped: %s\n", w.colorize(stopped, ColorRed))
+	}
+
+	if l := summary.Latency; l.Count > 0 {
+		fmt.Printf("Latency: min %v, mean %v, p50 %v, p90 %v, p95 %v, p99 %v, max %v\n",
+			round(l.Min), round(l.Mean()), round(l.Percentile(50)), round(l.Percentile(90)),
+			round(l.Percentile(95)), round(l.Percentile(99)), round(l.Max))
+	}
+
+	if len(summary.StatusCodes) > 0 {
+		codes := slices.Sorted(maps.Keys(summary.StatusCodes))
+		parts := make([]string, len(codes))
+		for i, code := range codes {
+			parts[i] = fmt.Sprintf("%d×%d", code, summary.StatusCodes[code])
+		}
+		fmt.Printf("Status codes: %s\n", strings.Join(parts, ", "))
+	}
+
+	if len(summary.ErrorClasses) > 0 {
+		classes := slices.Sorted(maps.Keys(summary.ErrorClasses))
+		parts := make([]string, len(classes))
+		for i, class := range classes {
+			parts[i] = fmt.Sprintf("%s×%d", class, summary.ErrorClasses[class])
+		}
+		fmt.Printf("Errors: %s\n", w.colorize(strings.Join(parts, ", "), ColorRed))
+	}
+
+	if histogram := summary.Latency.Histogram(); len(histogram) > 1 {
+		fmt.Println("Latency distribution:")
+		fmt.Print(FormatHistogram(histogram, histogramWidth))
+	}
+
+	if summary.Baseline != "" {
+		w.writeChanges(summary)
+	}
+}
+
+// writeChanges выводит отличия от базового запуска; регрессии выделяются
+// красным, восстановившиеся URL - зеленым
+func (w *Writer) writeChanges(summary Summary) {
+	if len(summary.Changes) == 0 {
+		fmt.Printf("No changes since baseline %s\n", summary.Baseline)
+		return
+	}
+
+	regressions := summary.Regressions()
+	fmt.Printf("Changes since baseline %s: %s\n", summary.Baseline,
+		w.colorize(fmt.Sprintf("%d %s", regressions, plural(regressions, "regression", "regressions")), ColorRed))
+
+	for _, c := range summary.Changes {
+		line := fmt.Sprintf("  %s %s: %s", c.Kind, c.URL, c.Message)
+		switch {
+		case c.Regression:
+			line = w.colorize(line, ColorRed)
+		case c.Kind == types.ChangeRecovered:
+			line = w.colorize(line, ColorGreen)
+		}
+		fmt.Println(line)
+	}
+}
+
+// histogramWidth - длина самой длинной полосы гистограммы в символах
+const histogramWidth = 40
+
+// FormatHistogram рисует гистограмму задержек, по строке на корзину
+func FormatHistogram(buckets []HistogramBucket, width int) string {
+	largest := 0
+	for _, b := range buckets {
+		largest = max(largest, b.Count)
+	}
+
+	var sb strings.Builder
+	for i, b := range buckets {
+		label := fmt.Sprintf("≤%v", b.UpperBound)
+		if b.UpperBound == 0 {
+			label = fmt.Sprintf(">%v", buckets[i-1].UpperBound)
+		}
+
+		bar := b.Count * width / largest
+		if bar == 0 && b.Count > 0 {
+			bar = 1
+		}
+		fmt.Fprintf(&sb, "  %7s %s %d\n", label, strings.Repeat("█", bar), b.Count)
+	}
+	return sb.String()
 }
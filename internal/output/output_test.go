@@ -0,0 +1,62 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func TestFormatTiming(t *testing.T) {
+	testCases := []struct {
+		timing   types.Timing
+		expected string
+	}{
+		{
+			timing: types.Timing{
+				DNS:      2 * time.Millisecond,
+				Connect:  3 * time.Millisecond,
+				TLS:      10 * time.Millisecond,
+				TTFB:     45*time.Millisecond + 400*time.Nanosecond,
+				Transfer: time.Millisecond,
+			},
+			expected: "dns 2ms, connect 3ms, tls 10ms, ttfb 45ms, transfer 1ms",
+		},
+		{
+			timing:   types.Timing{TTFB: 20 * time.Millisecond, Transfer: 5 * time.Millisecond, ConnReused: true},
+			expected: "reused connection, ttfb 20ms, transfer 5ms",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := FormatTiming(tc.timing); got != tc.expected {
+			t.Errorf("Expected %q, got %q", tc.expected, got)
+		}
+	}
+}
+
+func TestSummary_Distribution(t *testing.T) {
+	var summary Summary
+	for _, r := range sampleResults() {
+		summary.Add(r)
+	}
+	summary.Add(types.Result{URL: "http://missing.com", StatusCode: 404, Duration: 3 * time.Millisecond})
+
+	if summary.Latency.Count != 2 || summary.Latency.Max != 3*time.Millisecond {
+		t.Errorf("Expected latency of responses only, got %+v", summary.Latency)
+	}
+	if summary.StatusCodes[200] != 1 || summary.StatusCodes[404] != 1 || len(summary.StatusCodes) != 2 {
+		t.Errorf("Unexpected status codes: %v", summary.StatusCodes)
+	}
+	if summary.ErrorClasses["timeout"] != 1 || len(summary.ErrorClasses) != 1 {
+		t.Errorf("Unexpected error classes: %v", summary.ErrorClasses)
+	}
+
+	js := NewJSONSummary(summary)
+	if js.Latency == nil || js.Latency.MaxMs != 3 || len(js.Latency.Histogram) == 0 {
+		t.Errorf("Unexpected JSON latency: %+v", js.Latency)
+	}
+	if js.StatusCodes[404] != 1 || js.ErrorClasses["timeout"] != 1 {
+		t.Errorf("Unexpected JSON distributions: %v %v", js.StatusCodes, js.ErrorClasses)
+	}
+}
@@ -0,0 +1,136 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// TemplateWriter выводит результаты и итоги по пользовательским шаблонам
+// text/template. Если один из шаблонов не задан, эта часть выводится
+// обычным Writer.
+type TemplateWriter struct {
+	*Writer
+
+	out     io.Writer
+	result  *template.Template
+	summary *template.Template
+	err     error
+}
+
+// NewTemplateWriter разбирает шаблоны результата (над types.Result)
+// и итогов (над Summary). Шаблоны сразу выполняются на примере данных,
+// чтобы ошибки в полях обнаружились до начала проверки.
+func NewTemplateWriter(out io.Writer, config Config, resultFormat, summaryFormat string) (*TemplateWriter, error) {
+	w := &TemplateWriter{Writer: NewWriter(config), out: out}
+	funcs := w.funcs()
+
+	var err error
+	if resultFormat != "" {
+		if w.result, err = template.New("format").Funcs(funcs).Parse(resultFormat); err != nil {
+			return nil, fmt.Errorf("invalid -format: %w", err)
+		}
+		sample := types.Result{URL: "https://example.com", Method: "GET", StatusCode: 200, Duration: time.Millisecond}
+		if err := w.result.Execute(io.Discard, sample); err != nil {
+			return nil, fmt.Errorf("invalid -format: %w", err)
+		}
+	}
+
+	if summaryFormat != "" {
+		if w.summary, err = template.New("summary-format").Funcs(funcs).Parse(summaryFormat); err != nil {
+			return nil, fmt.Errorf("invalid -summary-format: %w", err)
+		}
+		if err := w.summary.Execute(io.Discard, Summary{Total: 1, Success: 1}); err != nil {
+			return nil, fmt.Errorf("invalid -summary-format: %w", err)
+		}
+	}
+
+	return w, nil
+}
+
+func (w *TemplateWriter) WriteProgress(current, total int, result types.Result) {
+	if w.result == nil {
+		w.Writer.WriteProgress(current, total, result)
+		return
+	}
+	w.execute(w.result, result)
+}
+
+func (w *TemplateWriter) WriteSummary(summary Summary) {
+	if w.summary == nil {
+		w.Writer.WriteSummary(summary)
+		return
+	}
+	w.execute(w.summary, summary)
+}
+
+// Err возвращает первую ошибку выполнения шаблона или записи
+func (w *TemplateWriter) Err() error {
+	return w.err
+}
+
+// execute выводит шаблон, добавляя перевод строки, если его нет в конце
+func (w *TemplateWriter) execute(tmpl *template.Template, data any) {
+	if w.err != nil {
+		return
+	}
+
+	var sb strings.Builder
+	if w.err = tmpl.Execute(&sb, data); w.err != nil {
+		return
+	}
+	if !strings.HasSuffix(sb.String(), "\n") {
+		sb.WriteByte('\n')
+	}
+	_, w.err = io.WriteString(w.out, sb.String())
+}
+
+// templateColors - цвета, доступные в функции color
+var templateColors = map[string]string{
+	"red":    ColorRed,
+	"green":  ColorGreen,
+	"yellow": ColorYellow,
+}
+
+func (w *TemplateWriter) funcs() template.FuncMap {
+	return template.FuncMap{
+		// ms переводит длительность в миллисекунды
+		"ms": func(d time.Duration) float64 {
+			return durationMs(d)
+		},
+		// color окрашивает значение, если цветной вывод включен
+		"color": func(name string, v any) (string, error) {
+			color, ok := templateColors[name]
+			if !ok {
+				return "", fmt.Errorf("unknown color %q. Use red, green or yellow", name)
+			}
+			return w.colorize(fmt.Sprint(v), color), nil
+		},
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		// pad дополняет значение пробелами справа, padLeft - слева
+		"pad": func(width int, v any) string {
+			s := fmt.Sprint(v)
+			return s + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s)))
+		},
+		"padLeft": func(width int, v any) string {
+			s := fmt.Sprint(v)
+			return strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s))) + s
+		},
+		"errorClass": func(err error) string {
+			if err == nil {
+				return ""
+			}
+			return checker.ErrorClass(err)
+		},
+	}
+}
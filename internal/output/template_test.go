@@ -0,0 +1,70 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTemplateWriter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		format   string
+		summary  string
+		expected string
+	}{
+		{
+			name:   "fields and helpers",
+			format: `{{pad 16 .URL}}|{{padLeft 4 .StatusCode}}|{{ms .Duration}}|{{errorClass .Error}}|{{color "red" "x"}}`,
+			expected: "http://ok.com   | 200|1.5||x\n" +
+				"http://down.com |   0|5000|timeout|x\n",
+		},
+		{
+			name:     "json and summary",
+			format:   `{{json .URL}} {{if .IsSuccess}}up{{else}}down{{end}}` + "\n",
+			summary:  `{{.Success}}/{{.Total}} in {{ms .Duration}}ms`,
+			expected: "\"http://ok.com\" up\n\"http://down.com\" down\n1/2 in 5000ms\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		w, err := NewTemplateWriter(&buf, Config{}, tc.format, tc.summary)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		for i, r := range sampleResults() {
+			w.WriteProgress(i+1, 2, r)
+		}
+		if tc.summary != "" {
+			w.WriteSummary(Summary{Total: 2, Success: 1, Failed: 1, Duration: 5 * time.Second})
+		}
+
+		if w.Err() != nil {
+			t.Fatalf("%s: expected no error, got %v", tc.name, w.Err())
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, buf.String())
+		}
+	}
+}
+
+func TestNewTemplateWriter_Invalid(t *testing.T) {
+	testCases := []struct {
+		format  string
+		summary string
+	}{
+		{format: `{{.URL`},
+		{format: `{{.Missing}}`},
+		{format: `{{color "blue" .URL}}`},
+		{format: `{{unknown .URL}}`},
+		{summary: `{{.Latency.P99}}`},
+	}
+
+	for _, tc := range testCases {
+		if _, err := NewTemplateWriter(&bytes.Buffer{}, Config{}, tc.format, tc.summary); err == nil {
+			t.Errorf("Expected error for format %q, summary %q", tc.format, tc.summary)
+		}
+	}
+}
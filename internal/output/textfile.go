@@ -0,0 +1,127 @@
+package output
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/metrics"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// TextfileWriter собирает результаты и при выводе итогов записывает их
+// в файл для textfile collector из node_exporter. Файл заменяется целиком
+// через переименование, чтобы collector не прочитал его наполовину.
+type TextfileWriter struct {
+	path string
+	err  error
+
+	success  metrics.Metric
+	status   metrics.Metric
+	duration metrics.Metric
+	// index - номер значения для URL; повторная проверка URL заменяет
+	// прежнее значение, иначе collector отвергнет файл с дубликатами
+	index map[string]int
+
+	now func() time.Time
+}
+
+func NewTextfileWriter(path string) *TextfileWriter {
+	return &TextfileWriter{
+		path: path,
+		success: metrics.Metric{
+			Name: "urlcheck_success",
+			Help: "Whether the check succeeded, including expectations",
+			Type: metrics.TypeGauge,
+		},
+		status: metrics.Metric{
+			Name: "urlcheck_http_status_code",
+			Help: "Response status code, 0 if there was no response",
+			Type: metrics.TypeGauge,
+		},
+		duration: metrics.Metric{
+			Name: "urlcheck_duration_seconds",
+			Help: "Time until the response headers were received",
+			Type: metrics.TypeGauge,
+		},
+		index: map[string]int{},
+		now:   time.Now,
+	}
+}
+
+func (w *TextfileWriter) WriteProgress(current, total int, result types.Result) {
+	labels := []metrics.Label{
+		{Name: "url", Value: result.URL},
+		{Name: "host", Value: hostLabel(result.URL)},
+	}
+
+	i, ok := w.index[result.URL]
+	if !ok {
+		i = len(w.success.Samples)
+		w.index[result.URL] = i
+		w.success.Samples = append(w.success.Samples, metrics.Sample{})
+		w.status.Samples = append(w.status.Samples, metrics.Sample{})
+		w.duration.Samples = append(w.duration.Samples, metrics.Sample{})
+	}
+
+	w.success.Samples[i] = metrics.Sample{Labels: labels, Value: metrics.Bool(result.IsSuccess())}
+	w.status.Samples[i] = metrics.Sample{Labels: labels, Value: float64(result.StatusCode)}
+	w.duration.Samples[i] = metrics.Sample{Labels: labels, Value: result.Duration.Seconds()}
+}
+
+func (w *TextfileWriter) WriteSummary(summary Summary) {
+	ms := []metrics.Metric{
+		w.success,
+		w.status,
+		w.duration,
+		metrics.Gauge("urlcheck_last_run_timestamp_seconds", "Completion time of the last run since unix epoch in seconds",
+			float64(w.now().Unix())),
+		metrics.Gauge("urlcheck_last_run_duration_seconds", "Duration of the last run",
+			summary.Duration.Seconds()),
+		metrics.Gauge("urlcheck_last_run_failed", "Number of URLs that failed in the last run",
+			float64(summary.Failed)),
+	}
+
+	w.err = writeFileAtomic(w.path, ms)
+}
+
+// Err возвращает ошибку записи файла
+func (w *TextfileWriter) Err() error {
+	return w.err
+}
+
+// writeFileAtomic пишет метрики во временный файл в том же каталоге
+// и переименовывает его в path
+func writeFileAtomic(path string, ms []metrics.Metric) error {
+	// Имя временного файла не оканчивается на .prom, поэтому collector его пропустит
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := metrics.Write(tmp, ms); err != nil {
+		tmp.Close()
+		return err
+	}
+	// CreateTemp создает файл только для владельца, а collector может
+	// работать от другого пользователя
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func hostLabel(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
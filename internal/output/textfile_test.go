@@ -0,0 +1,86 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextfileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urlcheck.prom")
+	if err := os.WriteFile(path, []byte("stale\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewTextfileWriter(path)
+	w.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	results := sampleResults()
+	// Повторная проверка того же URL заменяет прежнее значение
+	retry := results[1]
+	retry.Error = nil
+	retry.StatusCode = 200
+	retry.Duration = 250 * time.Millisecond
+	results = append(results, retry)
+
+	for i, r := range results {
+		w.WriteProgress(i+1, len(results), r)
+	}
+	w.WriteSummary(Summary{Total: 3, Success: 2, Failed: 1, Duration: 5 * time.Second})
+
+	if w.Err() != nil {
+		t.Fatalf("Expected no error, got %v", w.Err())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+
+	for _, line := range []string{
+		`urlcheck_success{url="http://ok.com",host="ok.com"} 1`,
+		`urlcheck_success{url="http://down.com",host="down.com"} 1`,
+		`urlcheck_http_status_code{url="http://ok.com",host="ok.com"} 200`,
+		`urlcheck_duration_seconds{url="http://ok.com",host="ok.com"} 0.0015`,
+		`urlcheck_duration_seconds{url="http://down.com",host="down.com"} 0.25`,
+		`urlcheck_last_run_timestamp_seconds 1.7e+09`,
+		`urlcheck_last_run_duration_seconds 5`,
+		`urlcheck_last_run_failed 1`,
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("Expected line %q, got:\n%s", line, got)
+		}
+	}
+
+	if n := strings.Count(got, "urlcheck_success{"); n != 2 {
+		t.Errorf("Expected 2 urlcheck_success samples, got %d", n)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the metrics file in the directory, got %d entries", len(entries))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("Expected mode 0644, got %v", info.Mode().Perm())
+	}
+}
+
+func TestTextfileWriter_MissingDirectory(t *testing.T) {
+	w := NewTextfileWriter(filepath.Join(t.TempDir(), "missing", "urlcheck.prom"))
+	w.WriteSummary(Summary{})
+
+	if w.Err() == nil {
+		t.Error("Expected error for missing directory")
+	}
+}
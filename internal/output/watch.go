@@ -0,0 +1,72 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+	"github.com/nashabanov/urlcheck/internal/watch"
+)
+
+// TransitionWriter выводит смену состояния целей в режиме watch
+type TransitionWriter interface {
+	WriteTransition(t watch.Transition)
+}
+
+func (w *Writer) WriteTransition(t watch.Transition) {
+	status := w.colorize("✓ "+watch.StateUp, ColorGreen)
+	if t.To == watch.StateDown {
+		status = w.colorize("✗ "+watch.StateDown, ColorRed)
+	}
+
+	details := "(" + FailureReason(t.Result) + ")"
+	if t.To == watch.StateUp {
+		details += fmt.Sprintf(", down for %v", t.Outage.Round(time.Second))
+	}
+
+	fmt.Printf("%s %s %s %s\n", t.At.Format(time.DateTime), status, t.URL, details)
+}
+
+// FailureReason кратко описывает исход проверки: ошибку, невыполненные
+// ожидания или код ответа
+func FailureReason(result types.Result) string {
+	if result.Error != nil {
+		return result.Error.Error()
+	}
+
+	reason := fmt.Sprintf("%d, %v", result.StatusCode, result.Duration.Round(time.Millisecond))
+	var messages []string
+	for _, a := range result.FailedAssertions() {
+		messages = append(messages, a.Message)
+	}
+	if len(messages) > 0 {
+		reason += ": " + strings.Join(messages, "; ")
+	}
+	return reason
+}
+
+// JSONTransition - смена состояния цели в структурированном выводе
+type JSONTransition struct {
+	Type     string     `json:"type"`
+	URL      string     `json:"url"`
+	From     string     `json:"from,omitempty"`
+	To       string     `json:"to"`
+	Time     time.Time  `json:"time"`
+	OutageMs float64    `json:"outage_ms,omitempty"`
+	Result   JSONResult `json:"result"`
+}
+
+// WriteTransition выводит смену состояния отдельной строкой
+// независимо от режима: в watch итогового документа нет
+func (w *JSONWriter) WriteTransition(t watch.Transition) {
+	w.encode(JSONTransition{
+		Type:     "transition",
+		URL:      t.URL,
+		From:     t.From,
+		To:       t.To,
+		Time:     t.At,
+		OutageMs: durationMs(t.Outage),
+		Result:   NewJSONResult(t.Result),
+	})
+}
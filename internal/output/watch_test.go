@@ -0,0 +1,52 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+	"github.com/nashabanov/urlcheck/internal/watch"
+)
+
+func TestJSONWriter_Transition(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONWriter(&buf, true)
+
+	w.WriteTransition(watch.Transition{
+		URL:    "http://example.com",
+		From:   watch.StateDown,
+		To:     watch.StateUp,
+		At:     time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		Outage: 90 * time.Second,
+		Result: types.Result{URL: "http://example.com", StatusCode: 200},
+	})
+
+	var jt JSONTransition
+	if err := json.Unmarshal(buf.Bytes(), &jt); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, buf.String())
+	}
+	if jt.Type != "transition" || jt.From != "DOWN" || jt.To != "UP" || jt.OutageMs != 90000 {
+		t.Errorf("Unexpected transition: %+v", jt)
+	}
+	if jt.Result.StatusCode != 200 || !jt.Result.Success {
+		t.Errorf("Unexpected result: %+v", jt.Result)
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	result := types.Result{
+		StatusCode: 200,
+		Duration:   1500 * time.Millisecond,
+		Assertions: []types.Assertion{
+			{Name: "latency", Message: "latency 1.5s exceeds 1s"},
+			{Name: "status", Passed: true},
+		},
+	}
+
+	expected := "200, 1.5s: latency 1.5s exceeds 1s"
+	if got := FailureReason(result); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -1,10 +1,251 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Target - проверяемый URL, параметры запроса и ожидания к ответу
+type Target struct {
+	URL string
+
+	// Method - HTTP-метод; пустая строка означает GET
+	Method  string
+	Headers http.Header
+	Body    []byte
+	// HeadFallback повторяет HEAD-запрос методом GET, если сервер
+	// не поддерживает HEAD (405 или 501)
+	HeadFallback bool
+
+	// Interval - период проверки в режиме watch; 0 - общий период
+	Interval time.Duration
+
+	// Tags - метки цели из файла конфигурации, например critical
+	Tags []string
+
+	// Referrer - страница, на которой найдена ссылка, в режиме crawl
+	Referrer string
+	// KeepBody сохраняет тело ответа в результате, даже если его
+	// не проверяют ожидания, например для разбора ссылок
+	KeepBody bool
+
+	Expect Expectations
+}
+
+// NewTargets создает цели без ожиданий для списка URL
+func NewTargets(urls []string) []Target {
+	targets := make([]Target, len(urls))
+	for i, u := range urls {
+		targets[i] = Target{URL: u}
+	}
+	return targets
+}
+
+// Expectations описывает, каким должен быть ответ, чтобы проверка считалась
+// успешной. Пустой список Statuses означает любой код 2xx.
+type Expectations struct {
+	Statuses        []StatusRange
+	BodyContains    []string
+	BodyNotContains []string
+	BodyMatches     []*regexp.Regexp
+	BodyNotMatches  []*regexp.Regexp
+	MaxLatency      time.Duration
+	Headers         []HeaderExpectation
+
+	// MaxRedirects ограничивает длину цепочки редиректов, 0 - без ограничения
+	MaxRedirects int
+	// RequireHTTPS требует, чтобы ответ был получен по https и цепочка
+	// редиректов не переходила с https на http
+	RequireHTTPS bool
+}
+
+// NeedsBody сообщает, нужно ли читать тело ответа для проверки ожиданий
+func (e Expectations) NeedsBody() bool {
+	return len(e.BodyContains) > 0 || len(e.BodyNotContains) > 0 ||
+		len(e.BodyMatches) > 0 || len(e.BodyNotMatches) > 0
+}
+
+// StatusRange - диапазон допустимых кодов ответа, включая границы
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+func (r StatusRange) Contains(code int) bool {
+	return code >= r.Min && code <= r.Max
+}
+
+// HeaderExpectation требует наличия заголовка; если Pattern задан,
+// значение заголовка должно ему соответствовать
+type HeaderExpectation struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
 
 type Result struct {
 	URL        string
+	Method     string
 	StatusCode int
 	Duration   time.Duration
 	Error      error
+
+	// StartedAt - время начала проверки, то есть первой попытки
+	StartedAt time.Time
+
+	// Attempts - исходы всех попыток, включая последнюю
+	Attempts []Attempt
+
+	// Tags - метки проверенной цели
+	Tags []string
+
+	// Referrer - страница, ссылающаяся на URL, в режиме crawl
+	Referrer string
+
+	// Redirects - пройденные редиректы по порядку, FinalURL - адрес,
+	// с которого получен ответ
+	Redirects []Redirect
+	FinalURL  string
+
+	// TLS заполняется для ответов, полученных по https
+	TLS *TLSInfo
+
+	// Timing - фазы последнего запроса; Duration охватывает все фазы,
+	// кроме передачи тела
+	Timing Timing
+
+	// Warnings - замечания, не влияющие на успех проверки,
+	// например скорое истечение сертификата
+	Warnings []string
+
+	// ContentLength - размер тела ответа по заголовку Content-Length, а без
+	// него - по числу прочитанных байт; -1, если размер неизвестен.
+	// Заполняется только для полученных ответов.
+	ContentLength int64
+
+	// Header и Body нужны только для проверки ожиданий; Body читается,
+	// если цель проверяет тело ответа
+	Header http.Header
+	Body   []byte
+
+	// Assertions - результаты проверки ожиданий; nil, если они не проверялись
+	Assertions []Assertion
+}
+
+// Attempt - исход одной попытки запроса
+type Attempt struct {
+	StatusCode int
+	Duration   time.Duration
+	Error      error
+}
+
+// Redirect - один шаг цепочки редиректов: запрошенный URL, полученный
+// код 3xx и время до его получения
+type Redirect struct {
+	URL        string
+	StatusCode int
+	Duration   time.Duration
+}
+
+// Timing - длительность фаз запроса. Для соединения, взятого из пула
+// (ConnReused), DNS, Connect и TLS равны нулю.
+type Timing struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// TTFB - от отправки запроса до первого байта ответа
+	TTFB time.Duration
+	// Transfer - от первого байта ответа до конца чтения тела
+	// (не больше checker.MaxBodySize)
+	Transfer time.Duration
+
+	ConnReused bool
+}
+
+// TLSInfo - параметры TLS-соединения, по которому получен ответ
+type TLSInfo struct {
+	Version     string
+	CipherSuite string
+	// Certificates - цепочка в порядке, присланном сервером, первым идет leaf
+	Certificates []Certificate
+}
+
+// Certificate - основные поля сертификата из цепочки сервера
+type Certificate struct {
+	Subject  string
+	Issuer   string
+	SANs     []string
+	NotAfter time.Time
+}
+
+// Assertion - результат проверки одного ожидания
+type Assertion struct {
+	Name    string
+	Passed  bool
+	Message string
+}
+
+// IsSuccess сообщает, успешна ли проверка. Если ожидания проверялись,
+// успех означает выполнение всех ожиданий, иначе - код 2xx без ошибки.
+func (r Result) IsSuccess() bool {
+	if r.Error != nil {
+		return false
+	}
+	if r.Assertions != nil {
+		return len(r.FailedAssertions()) == 0
+	}
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// FailedAssertions возвращает невыполненные ожидания
+func (r Result) FailedAssertions() []Assertion {
+	var failed []Assertion
+	for _, a := range r.Assertions {
+		if !a.Passed {
+			failed = append(failed, a)
+		}
+	}
+	return failed
+}
+
+// Виды отличий от базового запуска
+const (
+	// ChangeFailing - URL был успешен, теперь нет (регрессия)
+	ChangeFailing = "failing"
+	// ChangeRecovered - URL был неуспешен, теперь успешен
+	ChangeRecovered = "recovered"
+	// ChangeStatus - изменился код ответа
+	ChangeStatus = "status"
+	// ChangeSlower - задержка выросла больше порога (регрессия)
+	ChangeSlower = "slower"
+	// ChangeNew - URL отсутствует в базовом запуске
+	ChangeNew = "new"
+	// ChangeMissing - URL из базового запуска не проверялся
+	ChangeMissing = "missing"
+)
+
+// Change - отличие результата проверки от базового запуска
+type Change struct {
+	URL     string
+	Kind    string
+	Message string
+	// Regression - изменение к худшему; только такие изменения
+	// влияют на код выхода при сравнении с базовым запуском
+	Regression bool
+}
+
+// FieldError - ошибка в конкретном поле описания цели; Field совпадает
+// с именем поля в файле конфигурации
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
 }
@@ -0,0 +1,180 @@
+package watch
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+	"github.com/nashabanov/urlcheck/internal/worker"
+)
+
+// Состояния цели
+const (
+	StateUp   = "UP"
+	StateDown = "DOWN"
+)
+
+// Transition - смена состояния цели. При первой проверке From пуст.
+type Transition struct {
+	URL  string
+	From string
+	To   string
+	At   time.Time
+	// Outage - продолжительность простоя при переходе DOWN -> UP
+	Outage time.Duration
+	Result types.Result
+}
+
+// Monitor периодически проверяет цели и сообщает о смене их состояния.
+// Цель считается работающей (UP), если ее проверка успешна. Каждая цель
+// планируется отдельно, поэтому медленная проверка одной цели не
+// задерживает проверки остальных.
+type Monitor struct {
+	Checker checker.Checker
+	Worker  *worker.Worker
+
+	// Interval - период проверки для целей без собственного Interval
+	Interval time.Duration
+
+	// OnTransition вызывается последовательно из одной горутины. О целях,
+	// работающих с первой проверки, не сообщается.
+	OnTransition func(Transition)
+}
+
+// targetState - состояние одной цели между проверками. next и running
+// меняет только планировщик, state и downSince - только обработка результатов.
+type targetState struct {
+	target    types.Target
+	state     string
+	downSince time.Time
+	next      time.Time
+	running   bool
+}
+
+// Run проверяет цели, пока не будет отменен ctx. Цели с одинаковым URL
+// проверяются один раз, используется первая из них. Interval отсчитывается
+// от начала проверки; следующая проверка цели начинается не раньше, чем
+// закончится предыдущая. Отмена ctx - штатное завершение, ошибка при этом
+// не возвращается.
+func (m *Monitor) Run(ctx context.Context, targets []types.Target) error {
+	var states []*targetState
+	byURL := map[string]*targetState{}
+	for _, t := range targets {
+		if _, ok := byURL[t.URL]; ok {
+			continue
+		}
+		s := &targetState{target: t}
+		states = append(states, s)
+		byURL[t.URL] = s
+	}
+
+	// Планировщик выполняется в горутине воркера, поэтому завершения
+	// передаются ему через канал. В обработке одновременно не больше одной
+	// проверки каждой цели, и отправка в буфер не блокируется.
+	finished := make(chan *targetState, len(states))
+
+	// Свой контекст останавливает планировщик, если воркер завершился
+	// с ошибкой без отмены ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	err := m.Worker.RunStream(ctx, m.Checker, m.schedule(ctx, states, finished), 0,
+		func(_, _ int, result *types.Result) {
+			if s := byURL[result.URL]; s != nil {
+				m.update(s, *result)
+				finished <- s
+			}
+		})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// schedule выдает цели по мере наступления их срока, пока не отменен ctx
+func (m *Monitor) schedule(
+	ctx context.Context,
+	states []*targetState,
+	finished <-chan *targetState,
+) iter.Seq[types.Target] {
+	return func(yield func(types.Target) bool) {
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+
+		for {
+			now := time.Now()
+			var next time.Time
+			for _, s := range states {
+				if s.running {
+					continue
+				}
+				if !s.next.After(now) {
+					s.running = true
+					s.next = now.Add(m.interval(s.target))
+					if !yield(s.target) {
+						return
+					}
+					continue
+				}
+				if next.IsZero() || s.next.Before(next) {
+					next = s.next
+				}
+			}
+
+			var wait <-chan time.Time
+			if !next.IsZero() {
+				timer.Reset(time.Until(next))
+				wait = timer.C
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case s := <-finished:
+				s.running = false
+			case <-wait:
+			}
+		}
+	}
+}
+
+func (m *Monitor) interval(t types.Target) time.Duration {
+	if t.Interval > 0 {
+		return t.Interval
+	}
+	return m.Interval
+}
+
+// update применяет результат проверки и сообщает о смене состояния
+func (m *Monitor) update(s *targetState, result types.Result) {
+	now := time.Now()
+
+	state := StateDown
+	if result.IsSuccess() {
+		state = StateUp
+	}
+	if state == s.state || (s.state == "" && state == StateUp) {
+		s.state = state
+		return
+	}
+
+	t := Transition{
+		URL:    result.URL,
+		From:   s.state,
+		To:     state,
+		At:     now,
+		Result: result,
+	}
+	if state == StateDown {
+		s.downSince = now
+	} else {
+		t.Outage = now.Sub(s.downSince)
+	}
+	s.state = state
+
+	if m.OnTransition != nil {
+		m.OnTransition(t)
+	}
+}
@@ -0,0 +1,196 @@
+package watch
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+	"github.com/nashabanov/urlcheck/internal/worker"
+)
+
+// scriptedChecker отвечает по заранее заданной последовательности кодов
+// для каждого URL; после ее окончания повторяет последний код
+type scriptedChecker struct {
+	mu     sync.Mutex
+	script map[string][]int
+	calls  map[string]int
+}
+
+func newScriptedChecker(script map[string][]int) *scriptedChecker {
+	return &scriptedChecker{script: script, calls: map[string]int{}}
+}
+
+func (c *scriptedChecker) Check(ctx context.Context, target types.Target) *types.Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	codes := c.script[target.URL]
+	n := c.calls[target.URL]
+	c.calls[target.URL]++
+
+	return &types.Result{URL: target.URL, StatusCode: codes[min(n, len(codes)-1)]}
+}
+
+func (c *scriptedChecker) count(url string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[url]
+}
+
+func TestMonitor_Transitions(t *testing.T) {
+	c := newScriptedChecker(map[string][]int{
+		"http://flaky.com":  {200, 500, 500, 200},
+		"http://stable.com": {200},
+		"http://down.com":   {503},
+	})
+
+	var transitions []Transition
+	m := &Monitor{
+		Checker:      c,
+		Worker:       &worker.Worker{MaxWorkers: 2},
+		Interval:     10 * time.Millisecond,
+		OnTransition: func(t Transition) { transitions = append(transitions, t) },
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	targets := types.NewTargets([]string{"http://flaky.com", "http://stable.com", "http://down.com"})
+	if err := m.Run(ctx, targets); err != nil {
+		t.Fatalf("Expected clean stop, got %v", err)
+	}
+
+	expected := []struct{ url, from, to string }{
+		{"http://down.com", "", StateDown},
+		{"http://flaky.com", StateUp, StateDown},
+		{"http://flaky.com", StateDown, StateUp},
+	}
+
+	// Порядок внутри одного прохода зависит от воркеров
+	byURL := map[string][]Transition{}
+	for _, tr := range transitions {
+		byURL[tr.URL] = append(byURL[tr.URL], tr)
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected %d transitions, got %+v", len(expected), transitions)
+	}
+	for _, e := range expected {
+		got := byURL[e.url]
+		if len(got) == 0 {
+			t.Errorf("Expected transition %s -> %s for %s", e.from, e.to, e.url)
+			continue
+		}
+		if got[0].From != e.from || got[0].To != e.to {
+			t.Errorf("%s: expected %q -> %q, got %q -> %q", e.url, e.from, e.to, got[0].From, got[0].To)
+		}
+		byURL[e.url] = got[1:]
+	}
+
+	recovered := transitions[len(transitions)-1]
+	if recovered.To != StateUp || recovered.Outage < 15*time.Millisecond {
+		t.Errorf("Expected outage of about two intervals, got %+v", recovered)
+	}
+}
+
+func TestMonitor_TargetIntervals(t *testing.T) {
+	c := newScriptedChecker(map[string][]int{"http://fast.com": {200}, "http://slow.com": {200}})
+
+	m := &Monitor{
+		Checker:  c,
+		Worker:   &worker.Worker{MaxWorkers: 2},
+		Interval: 10 * time.Millisecond,
+	}
+
+	targets := types.NewTargets([]string{"http://fast.com", "http://slow.com", "http://fast.com"})
+	targets[1].Interval = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := m.Run(ctx, targets); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := c.count("http://slow.com"); n != 1 {
+		t.Errorf("Expected slow target to be checked once, got %d", n)
+	}
+	if n := c.count("http://fast.com"); n < 5 {
+		t.Errorf("Expected fast target to be checked repeatedly, got %d", n)
+	}
+}
+
+// slowChecker задерживает проверку URL из slow до отмены ctx
+type slowChecker struct {
+	*scriptedChecker
+	slow map[string]time.Duration
+}
+
+func (c slowChecker) Check(ctx context.Context, target types.Target) *types.Result {
+	select {
+	case <-time.After(c.slow[target.URL]):
+	case <-ctx.Done():
+	}
+	return c.scriptedChecker.Check(ctx, target)
+}
+
+func TestMonitor_SlowTargetDoesNotDelayOthers(t *testing.T) {
+	c := slowChecker{
+		scriptedChecker: newScriptedChecker(map[string][]int{"http://fast.com": {200}, "http://slow.com": {200}}),
+		slow:            map[string]time.Duration{"http://slow.com": time.Second},
+	}
+
+	m := &Monitor{
+		Checker:  c,
+		Worker:   &worker.Worker{MaxWorkers: 2},
+		Interval: 10 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	if err := m.Run(ctx, types.NewTargets([]string{"http://slow.com", "http://fast.com"})); err != nil {
+		t.Fatal(err)
+	}
+
+	// Следующая проверка медленной цели ждет окончания предыдущей
+	if n := c.count("http://slow.com"); n != 1 {
+		t.Errorf("Expected slow target to be checked once, got %d", n)
+	}
+	if n := c.count("http://fast.com"); n < 5 {
+		t.Errorf("Expected fast target to be checked while slow one runs, got %d", n)
+	}
+}
+
+func TestMonitor_StopsOnCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	m := &Monitor{
+		Checker:  newScriptedChecker(map[string][]int{"http://a.com": {200}}),
+		Worker:   &worker.Worker{MaxWorkers: 1},
+		Interval: time.Hour,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- m.Run(ctx, types.NewTargets([]string{"http://a.com"})) }()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected nil error on cancel, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to stop after cancel")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expected no leaked goroutines, before %d, after %d", before, after)
+	}
+}
@@ -0,0 +1,269 @@
+package worker
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// maxPending ограничивает число целей, ожидающих своей очереди в планировщике
+const maxPending = 1000
+
+// maxResolved ограничивает кэш IP-адресов хостов
+const maxResolved = 10000
+
+// job - цель вместе с ключом группы (хост или IP), по которой действуют лимиты
+type job struct {
+	target types.Target
+	key    string
+}
+
+type hostState struct {
+	queue     []types.Target
+	active    int
+	lastStart time.Time
+}
+
+// hostScheduler раздает цели воркерам так, чтобы к одному хосту шло не более
+// maxActive запросов одновременно, а их начала разделяла пауза не меньше delay.
+// Цели других хостов тем временем продолжают выдаваться.
+type hostScheduler struct {
+	maxActive int
+	delay     time.Duration
+
+	// limiter - общий лимит частоты. Токен берется до выдачи цели, чтобы
+	// пауза хоста отсчитывалась от фактического начала запроса, а не от
+	// выдачи цели воркеру, который еще ждет лимит.
+	limiter  *tokenBucket
+	hasToken bool
+	tokenAt  time.Time
+
+	hosts   map[string]*hostState
+	order   []string
+	next    int
+	pending int
+	sweepAt int
+}
+
+func newHostScheduler(maxActive int, delay time.Duration, limiter *tokenBucket) *hostScheduler {
+	return &hostScheduler{
+		maxActive: maxActive,
+		delay:     delay,
+		limiter:   limiter,
+		hosts:     make(map[string]*hostState),
+		sweepAt:   maxPending,
+	}
+}
+
+// run читает цели из in, выдает готовые к запуску в out и получает
+// завершения из done. out закрывается, когда in исчерпан и очередь пуста.
+func (s *hostScheduler) run(ctx context.Context, in <-chan job, out chan<- job, done <-chan string) {
+	defer close(out)
+
+	for in != nil || s.pending > 0 {
+		now := time.Now()
+		ready, wait := s.ready(now)
+		if ready != nil && s.limiter != nil {
+			if !s.hasToken {
+				s.tokenAt = now.Add(s.limiter.reserve())
+				s.hasToken = true
+			}
+			if remaining := s.tokenAt.Sub(now); remaining > 0 {
+				ready, wait = nil, remaining
+			}
+		}
+
+		var outCh chan<- job
+		var candidate job
+		if ready != nil {
+			outCh = out
+			candidate = *ready
+		}
+
+		inCh := in
+		if s.pending >= maxPending {
+			inCh = nil
+		}
+
+		var timer *time.Timer
+		var timerCh <-chan time.Time
+		if ready == nil && wait > 0 {
+			timer = time.NewTimer(wait)
+			timerCh = timer.C
+		}
+
+		select {
+		case outCh <- candidate:
+			s.start(candidate.key, time.Now())
+			s.hasToken = false
+		case j, ok := <-inCh:
+			if ok {
+				s.enqueue(j)
+			} else {
+				in = nil
+			}
+		case key := <-done:
+			s.finish(key)
+		case <-timerCh:
+		case <-ctx.Done():
+			return
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+		s.sweep(time.Now())
+	}
+}
+
+func (s *hostScheduler) enqueue(j job) {
+	h, ok := s.hosts[j.key]
+	if !ok {
+		h = &hostState{}
+		s.hosts[j.key] = h
+	}
+	if len(h.queue) == 0 {
+		s.order = append(s.order, j.key)
+	}
+	h.queue = append(h.queue, j.target)
+	s.pending++
+}
+
+// ready находит по кругу хост, цель которого можно запустить сейчас.
+// Если таких нет, возвращает время до ближайшего окончания паузы (0 - ждать нечего).
+func (s *hostScheduler) ready(now time.Time) (*job, time.Duration) {
+	var wait time.Duration
+
+	for i := 0; i < len(s.order); i++ {
+		idx := (s.next + i) % len(s.order)
+		key := s.order[idx]
+		h := s.hosts[key]
+
+		if s.maxActive > 0 && h.active >= s.maxActive {
+			continue
+		}
+
+		if s.delay > 0 && !h.lastStart.IsZero() {
+			if remaining := h.lastStart.Add(s.delay).Sub(now); remaining > 0 {
+				if wait == 0 || remaining < wait {
+					wait = remaining
+				}
+				continue
+			}
+		}
+
+		s.next = idx
+		return &job{target: h.queue[0], key: key}, 0
+	}
+
+	return nil, wait
+}
+
+func (s *hostScheduler) start(key string, now time.Time) {
+	h := s.hosts[key]
+	h.queue[0] = types.Target{}
+	h.queue = h.queue[1:]
+	h.active++
+	h.lastStart = now
+	s.pending--
+
+	if len(h.queue) == 0 {
+		s.removeFromOrder(key)
+	} else {
+		// Следующим получит очередь другой хост
+		s.next++
+	}
+}
+
+func (s *hostScheduler) finish(key string) {
+	if h, ok := s.hosts[key]; ok {
+		h.active--
+		s.cleanup(key, time.Now())
+	}
+}
+
+// cleanup забывает хост без ожидающих и активных запросов, если его пауза
+// уже не влияет на расписание
+func (s *hostScheduler) cleanup(key string, now time.Time) {
+	h := s.hosts[key]
+	if len(h.queue) == 0 && h.active == 0 && (s.delay == 0 || now.Sub(h.lastStart) >= s.delay) {
+		delete(s.hosts, key)
+	}
+}
+
+// sweep время от времени удаляет хосты, чья пауза истекла после завершения
+// последнего запроса, чтобы память не росла с числом разных хостов
+func (s *hostScheduler) sweep(now time.Time) {
+	if len(s.hosts) <= s.sweepAt {
+		return
+	}
+	for key := range s.hosts {
+		s.cleanup(key, now)
+	}
+	s.sweepAt = max(2*len(s.hosts), maxPending)
+}
+
+func (s *hostScheduler) removeFromOrder(key string) {
+	for i, k := range s.order {
+		if k == key {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			if s.next > i {
+				s.next--
+			}
+			return
+		}
+	}
+}
+
+// hostKey возвращает имя хоста цели в нижнем регистре
+func hostKey(target types.Target) string {
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// ipResolver группирует хосты по первому IP-адресу. Если адрес не удалось
+// определить, ключом остается имя хоста.
+type ipResolver struct {
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+func newIPResolver() *ipResolver {
+	return &ipResolver{cache: make(map[string]string)}
+}
+
+func (r *ipResolver) key(ctx context.Context, target types.Target) string {
+	host := hostKey(target)
+	if host == "" {
+		return host
+	}
+
+	r.mu.Lock()
+	ip, ok := r.cache[host]
+	r.mu.Unlock()
+	if ok {
+		return ip
+	}
+
+	ip = host
+	if addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host); err == nil && len(addrs) > 0 {
+		ip = addrs[0].IP.String()
+	}
+
+	r.mu.Lock()
+	if len(r.cache) >= maxResolved {
+		clear(r.cache)
+	}
+	r.cache[host] = ip
+	r.mu.Unlock()
+
+	return ip
+}
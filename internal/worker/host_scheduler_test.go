@@ -0,0 +1,232 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+// hostTrackingChecker запоминает время начала запросов и пиковую
+// параллельность по каждому хосту
+type hostTrackingChecker struct {
+	delay time.Duration
+
+	mu       sync.Mutex
+	active   map[string]int
+	peak     map[string]int
+	starts   map[string][]time.Time
+	finished map[string]time.Time
+}
+
+func newHostTrackingChecker(delay time.Duration) *hostTrackingChecker {
+	return &hostTrackingChecker{
+		delay:    delay,
+		active:   map[string]int{},
+		peak:     map[string]int{},
+		starts:   map[string][]time.Time{},
+		finished: map[string]time.Time{},
+	}
+}
+
+func (c *hostTrackingChecker) Check(ctx context.Context, target types.Target) *types.Result {
+	host := hostKey(target)
+
+	c.mu.Lock()
+	c.active[host]++
+	c.peak[host] = max(c.peak[host], c.active[host])
+	c.starts[host] = append(c.starts[host], time.Now())
+	c.mu.Unlock()
+
+	select {
+	case <-time.After(c.delay):
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	c.active[host]--
+	c.finished[host] = time.Now()
+	c.mu.Unlock()
+
+	return &types.Result{URL: target.URL, StatusCode: 200}
+}
+
+func hostTargets(host string, n int) []types.Target {
+	targets := make([]types.Target, n)
+	for i := range targets {
+		targets[i] = types.Target{URL: fmt.Sprintf("http://%s/page%d", host, i)}
+	}
+	return targets
+}
+
+func TestWorker_MaxPerHost(t *testing.T) {
+	worker := &Worker{MaxWorkers: 10, MaxPerHost: 2}
+	c := newHostTrackingChecker(30 * time.Millisecond)
+
+	targets := append(hostTargets("a.com", 10), hostTargets("b.com", 10)...)
+	callback, results := makeCollectorCallback()
+
+	if err := worker.Run(context.Background(), c, targets, callback); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(*results) != len(targets) {
+		t.Fatalf("Expected %d results, got %d", len(targets), len(*results))
+	}
+	for _, host := range []string{"a.com", "b.com"} {
+		if c.peak[host] > 2 {
+			t.Errorf("Expected at most 2 concurrent requests to %s, got %d", host, c.peak[host])
+		}
+		if c.peak[host] < 2 {
+			t.Errorf("Expected %s to use its 2 slots, got peak %d", host, c.peak[host])
+		}
+	}
+}
+
+func TestWorker_HostDelay(t *testing.T) {
+	delay := 50 * time.Millisecond
+	worker := &Worker{MaxWorkers: 5, HostDelay: delay}
+	c := newHostTrackingChecker(time.Millisecond)
+
+	callback, _ := makeCollectorCallback()
+	if err := worker.Run(context.Background(), c, hostTargets("a.com", 4), callback); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	starts := c.starts["a.com"]
+	if len(starts) != 4 {
+		t.Fatalf("Expected 4 requests, got %d", len(starts))
+	}
+	for i := 1; i < len(starts); i++ {
+		// Небольшой допуск на неточность таймеров
+		if gap := starts[i].Sub(starts[i-1]); gap < delay-5*time.Millisecond {
+			t.Errorf("Expected at least %v between requests, got %v", delay, gap)
+		}
+	}
+}
+
+func TestWorker_HostDelayWithRate(t *testing.T) {
+	delay := 50 * time.Millisecond
+	worker := &Worker{MaxWorkers: 20, HostDelay: delay, Rate: 100, Burst: 1}
+	c := newHostTrackingChecker(time.Millisecond)
+
+	// Запросы к a.com стоят в конце очереди общего лимита: пауза хоста
+	// должна соблюдаться между фактическими началами запросов
+	var targets []types.Target
+	for i := 0; i < 19; i++ {
+		targets = append(targets, types.Target{URL: fmt.Sprintf("http://other%d.com/", i)})
+	}
+	targets = append(targets, hostTargets("a.com", 3)...)
+
+	callback, _ := makeCollectorCallback()
+	if err := worker.Run(context.Background(), c, targets, callback); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	starts := c.starts["a.com"]
+	if len(starts) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(starts))
+	}
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap < delay-5*time.Millisecond {
+			t.Errorf("Expected at least %v between requests, got %v", delay, gap)
+		}
+	}
+}
+
+func TestWorker_ThrottledHostDoesNotBlockOthers(t *testing.T) {
+	worker := &Worker{MaxWorkers: 4, HostDelay: 100 * time.Millisecond}
+	c := newHostTrackingChecker(time.Millisecond)
+
+	// Медленный хост идет первым и заполнил бы все воркеры без планировщика
+	targets := hostTargets("slow.com", 5)
+	for i := 0; i < 5; i++ {
+		targets = append(targets, types.Target{URL: fmt.Sprintf("http://fast%d.com/", i)})
+	}
+
+	start := time.Now()
+	callback, _ := makeCollectorCallback()
+	if err := worker.Run(context.Background(), c, targets, callback); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		host := fmt.Sprintf("fast%d.com", i)
+		if finished := c.finished[host].Sub(start); finished > 80*time.Millisecond {
+			t.Errorf("Expected %s to finish while slow.com is throttled, took %v", host, finished)
+		}
+	}
+
+	if total := time.Since(start); total < 400*time.Millisecond {
+		t.Errorf("Expected slow.com requests to be spaced out, run took %v", total)
+	}
+}
+
+func TestWorker_HostLimitCancellation(t *testing.T) {
+	worker := &Worker{MaxWorkers: 2, MaxPerHost: 1, HostDelay: time.Second}
+	c := newHostTrackingChecker(time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	callback, _ := makeCollectorCallback()
+
+	start := time.Now()
+	err := worker.Run(ctx, c, hostTargets("a.com", 10), callback)
+
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Run to stop on cancellation, took %v", elapsed)
+	}
+}
+
+func TestWorker_HostLimitCancellationManyHosts(t *testing.T) {
+	var targets []types.Target
+	for i := 0; i < 200; i++ {
+		targets = append(targets, hostTargets(fmt.Sprintf("host%d.com", i), 25)...)
+	}
+
+	// После отмены планировщик перестает принимать завершения,
+	// и воркеры не должны зависнуть на их отправке
+	for i := 0; i < 20; i++ {
+		worker := &Worker{MaxWorkers: 16, MaxPerHost: 1}
+		callback, _ := makeCollectorCallback()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Duration(i)*time.Millisecond, cancel)
+
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- worker.Run(ctx, newHostTrackingChecker(time.Millisecond), targets, callback)
+		}()
+
+		select {
+		case err := <-errChan:
+			if err != context.Canceled {
+				t.Errorf("Iteration %d: expected context.Canceled, got %v", i, err)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Iteration %d: Run didn't return after cancellation", i)
+		}
+		cancel()
+	}
+}
+
+func TestHostKey(t *testing.T) {
+	testCases := map[string]string{
+		"http://Example.COM:8080/path": "example.com",
+		"https://example.com":          "example.com",
+		"::bad":                        "",
+	}
+
+	for input, expected := range testCases {
+		if got := hostKey(types.Target{URL: input}); got != expected {
+			t.Errorf("hostKey(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+}
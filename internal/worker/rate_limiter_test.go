@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nashabanov/urlcheck/internal/checker"
+	"github.com/nashabanov/urlcheck/internal/types"
+)
+
+func makeTargets(n int) []types.Target {
+	urls := make([]string, n)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("http://test%d.com", i)
+	}
+	return types.NewTargets(urls)
+}
+
+func TestWorker_Rate(t *testing.T) {
+	worker := &Worker{MaxWorkers: 5, Rate: 20, Burst: 1}
+	callback, results := makeCollectorCallback()
+
+	start := time.Now()
+	err := worker.Run(context.Background(), &checker.MockChecker{}, makeTargets(11), callback)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(*results) != 11 {
+		t.Fatalf("Expected 11 results, got %d", len(*results))
+	}
+
+	// Первая проверка сразу, остальные 10 - по одной каждые 50ms
+	if elapsed < 450*time.Millisecond || elapsed > 900*time.Millisecond {
+		t.Errorf("Expected about 500ms at 20 req/s, got %v", elapsed)
+	}
+}
+
+func TestWorker_RateBurst(t *testing.T) {
+	worker := &Worker{MaxWorkers: 5, Rate: 2, Burst: 5}
+	callback, _ := makeCollectorCallback()
+
+	start := time.Now()
+	err := worker.Run(context.Background(), &checker.MockChecker{}, makeTargets(5), callback)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Errorf("Expected burst of 5 to pass immediately, got %v", elapsed)
+	}
+}
+
+func TestWorker_RateCancellation(t *testing.T) {
+	worker := &Worker{MaxWorkers: 2, Rate: 1}
+	callback, results := makeCollectorCallback()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := worker.Run(ctx, &checker.MockChecker{}, makeTargets(10), callback)
+
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Run to stop while waiting for tokens, took %v", elapsed)
+	}
+	if len(*results) != 1 {
+		t.Errorf("Expected only the first check to pass the limiter, got %d", len(*results))
+	}
+}
+
+func TestTokenBucket_Refill(t *testing.T) {
+	b := newTokenBucket(100, 1)
+	ctx := context.Background()
+
+	if err := b.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := b.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond || elapsed > 50*time.Millisecond {
+		t.Errorf("Expected about 10ms wait at 100 req/s, got %v", elapsed)
+	}
+}
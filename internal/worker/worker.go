@@ -2,14 +2,59 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"iter"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/nashabanov/urlcheck/internal/checker"
 	"github.com/nashabanov/urlcheck/internal/types"
 )
 
+// bufferPerWorker - размер буферов каналов в расчете на одного воркера.
+// Буферы ограничены, чтобы память не росла с числом целей.
+const bufferPerWorker = 2
+
+// ErrStopped возвращается, когда FailFast остановил проверку после отказа.
+// Сама ошибка имеет тип *StopError; сравнивать ее нужно через errors.Is.
+var ErrStopped = errors.New("stopped after first failure")
+
+// StopError сообщает об остановке по FailFast
+type StopError struct {
+	// Interrupted - число начатых проверок, прерванных остановкой;
+	// о них не сообщается в callback
+	Interrupted int
+}
+
+func (e *StopError) Error() string { return ErrStopped.Error() }
+
+func (e *StopError) Is(target error) bool { return target == ErrStopped }
+
 type Worker struct {
 	MaxWorkers int
+
+	// MaxPerHost ограничивает число одновременных запросов к одному хосту;
+	// 0 - без ограничения
+	MaxPerHost int
+	// HostDelay - минимальная пауза между началами запросов к одному хосту
+	HostDelay time.Duration
+	// PerIP применяет лимиты к IP-адресу, а не к имени хоста
+	PerIP bool
+
+	// Rate ограничивает число проверок в секунду для всех воркеров вместе;
+	// 0 - без ограничения
+	Rate float64
+	// Burst - сколько проверок можно начать сразу сверх Rate
+	Burst int
+
+	// FailFast останавливает проверку после первого неуспешного результата
+	FailFast bool
+}
+
+// hostLimited сообщает, нужен ли планировщик по хостам
+func (w *Worker) hostLimited() bool {
+	return w.MaxPerHost > 0 || w.HostDelay > 0
 }
 
 func (w *Worker) validateMaxWorkers() {
@@ -18,21 +63,75 @@ func (w *Worker) validateMaxWorkers() {
 	}
 }
 
+// Run проверяет цели из списка
 func (w *Worker) Run(
 	ctx context.Context,
 	c checker.Checker,
-	urls []string,
+	targets []types.Target,
+	callback func(current, total int, result *types.Result),
+) error {
+	return w.RunStream(ctx, c, slices.Values(targets), len(targets), callback)
+}
+
+// RunStream проверяет цели по мере их поступления из targets.
+// total передается в callback как есть; 0 означает, что общее число неизвестно.
+func (w *Worker) RunStream(
+	ctx context.Context,
+	c checker.Checker,
+	targets iter.Seq[types.Target],
+	total int,
 	callback func(current, total int, result *types.Result),
 ) error {
 	w.validateMaxWorkers()
 
-	results := make(chan *types.Result, len(urls))
-	urlChan := make(chan string, len(urls))
+	// Остановка по FailFast отменяет свой контекст, чтобы ее можно было
+	// отличить от отмены вызывающим
+	parent := ctx
+	ctx, stop := context.WithCancel(parent)
+	defer stop()
+
+	bufferSize := w.MaxWorkers * bufferPerWorker
+	results := make(chan *types.Result, bufferSize)
+	jobs := make(chan job, bufferSize)
+
+	var limiter *tokenBucket
+	if w.Rate > 0 {
+		limiter = newTokenBucket(w.Rate, w.Burst)
+	}
+
+	// Без лимитов по хостам цели идут воркерам напрямую, иначе - через
+	// планировщик, которому воркеры сообщают о завершениях через done.
+	// Планировщик сам ждет общий лимит до выдачи цели.
+	var done chan string
+	feed := jobs
+	if w.hostLimited() {
+		done = make(chan string, w.MaxWorkers)
+		feed = make(chan job, bufferSize)
+		go newHostScheduler(w.MaxPerHost, w.HostDelay, limiter).run(ctx, feed, jobs, done)
+		limiter = nil
+	}
 
-	for _, u := range urls {
-		urlChan <- u
+	keyFunc := func(types.Target) string { return "" }
+	if w.hostLimited() {
+		keyFunc = hostKey
+		if w.PerIP {
+			resolver := newIPResolver()
+			keyFunc = func(t types.Target) string { return resolver.key(ctx, t) }
+		}
 	}
-	close(urlChan)
+
+	// Источник может блокироваться (например, stdin), поэтому его горутину
+	// не ждем: она завершится на ближайшей отправке после отмены ctx
+	go func() {
+		defer close(feed)
+		for t := range targets {
+			select {
+			case feed <- job{target: t, key: keyFunc(t)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
 	var wg sync.WaitGroup
 	wg.Add(w.MaxWorkers)
@@ -40,9 +139,32 @@ func (w *Worker) Run(
 	for i := 0; i < w.MaxWorkers; i++ {
 		go func() {
 			defer wg.Done()
-			for url := range urlChan {
-				result := c.Check(url)
-				results <- result
+			for j := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				if limiter != nil && limiter.Wait(ctx) != nil {
+					return
+				}
+				result := c.Check(ctx, j.target)
+				// Метки нужны после проверки, например для кода выхода
+				result.Tags = j.target.Tags
+				result.Referrer = j.target.Referrer
+				// После отмены планировщик не читает done, и отправка
+				// не должна блокировать воркер
+				if done != nil {
+					select {
+					case done <- j.key:
+					case <-ctx.Done():
+					}
+				}
+				// Завершенные до остановки проверки тоже доставляются:
+				// сбор результатов прекращается только при отмене parent
+				select {
+				case results <- result:
+				case <-parent.Done():
+					return
+				}
 			}
 		}()
 	}
@@ -53,19 +175,35 @@ func (w *Worker) Run(
 	}()
 
 	processedCount := 0
-	total := len(urls)
+	var stopped *StopError
 
 	for {
 		select {
 		case res, ok := <-results:
 			if !ok {
+				if stopped != nil {
+					return stopped
+				}
 				return nil
 			}
+			// После остановки дочитываем результаты: завершенные проверки
+			// сообщаются, прерванные отменой только подсчитываются
+			if stopped != nil && errors.Is(res.Error, context.Canceled) {
+				stopped.Interrupted++
+				continue
+			}
 			processedCount++
 			callback(processedCount, total, res)
+			if w.FailFast && stopped == nil && !res.IsSuccess() {
+				stopped = &StopError{}
+				stop()
+			}
 
-		case <-ctx.Done():
-			return ctx.Err()
+		case <-parent.Done():
+			// Дожидаемся завершения проверок, уже получивших отмену,
+			// чтобы ни одна горутина не пережила Run
+			wg.Wait()
+			return parent.Err()
 		}
 	}
 }
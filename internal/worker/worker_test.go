@@ -2,6 +2,11 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -51,7 +56,7 @@ func TestWorker_BasicCallback(t *testing.T) {
 	urls := []string{"http://example.com"}
 	ctx := context.Background()
 
-	err := worker.Run(ctx, &checker.MockChecker{}, urls, callback)
+	err := worker.Run(ctx, &checker.MockChecker{}, types.NewTargets(urls), callback)
 
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
@@ -74,7 +79,7 @@ func TestWorker_ContextCancellation(t *testing.T) {
 	errChan := make(chan error, 1)
 
 	go func() {
-		err := worker.Run(ctx, slowChecker, urls, callback)
+		err := worker.Run(ctx, slowChecker, types.NewTargets(urls), callback)
 		errChan <- err
 	}()
 
@@ -112,7 +117,7 @@ func TestWorker_MaxWorkers(t *testing.T) {
 
 	ctx := context.Background()
 
-	err := worker.Run(ctx, simpleChecker, urls, callback)
+	err := worker.Run(ctx, simpleChecker, types.NewTargets(urls), callback)
 	if err != nil {
 		t.Errorf("Worker.Run returned unexpected error: %v", err)
 	}
@@ -130,7 +135,7 @@ type atomicMockChecker struct {
 	maxConcurrent *int64
 }
 
-func (c *atomicMockChecker) Check(url string) *types.Result {
+func (c *atomicMockChecker) Check(_ context.Context, target types.Target) *types.Result {
 	// Увеличиваем счетчик активных
 	current := atomic.AddInt64(c.activeCount, 1)
 
@@ -149,7 +154,7 @@ func (c *atomicMockChecker) Check(url string) *types.Result {
 	atomic.AddInt64(c.activeCount, -1)
 
 	return &types.Result{
-		URL:        url,
+		URL:        target.URL,
 		StatusCode: 200,
 		Duration:   time.Millisecond * 200,
 		Error:      nil,
@@ -169,7 +174,7 @@ func TestWorker_Run_WithTimeout(t *testing.T) {
 
 	urls := []string{"http://very-slow.com"}
 
-	err := worker.Run(ctx, slowChecker, urls, callback)
+	err := worker.Run(ctx, slowChecker, types.NewTargets(urls), callback)
 
 	// Проверяем что получили timeout
 	if err != context.DeadlineExceeded {
@@ -181,3 +186,236 @@ func TestWorker_Run_WithTimeout(t *testing.T) {
 		t.Logf("Some results received before timeout: %d", len(*results))
 	}
 }
+
+func TestWorker_NoGoroutineLeakOnCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	worker := &Worker{MaxWorkers: 10}
+	callback, _ := makeCollectorCallback()
+
+	urls := make([]string, 50)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("http://slow%d.com", i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	err := worker.Run(ctx, &checker.MockChecker{Delay: 5 * time.Second}, types.NewTargets(urls), callback)
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	// Горутине, закрывающей канал результатов, нужно мгновение на выход
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expected at most %d goroutines after Run, got %d", before, after)
+	}
+}
+
+func TestWorker_RunStream_BoundedBuffering(t *testing.T) {
+	worker := &Worker{MaxWorkers: 2}
+
+	var yielded int64
+	endless := func(yield func(types.Target) bool) {
+		for i := 0; ; i++ {
+			atomic.AddInt64(&yielded, 1)
+			if !yield(types.Target{URL: fmt.Sprintf("http://test%d.com", i)}) {
+				return
+			}
+		}
+	}
+
+	var processed, reportedTotal int
+	callback := func(current, total int, result *types.Result) {
+		processed = current
+		reportedTotal = total
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	err := worker.RunStream(ctx, &checker.MockChecker{Delay: 20 * time.Millisecond}, endless, 0, callback)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if processed == 0 {
+		t.Fatal("Expected some results before deadline")
+	}
+	if reportedTotal != 0 {
+		t.Errorf("Expected unknown total 0, got %d", reportedTotal)
+	}
+
+	// Источник не должен читаться дальше, чем позволяют ограниченные буферы
+	readAhead := atomic.LoadInt64(&yielded) - int64(processed)
+	if limit := int64(worker.MaxWorkers*bufferPerWorker*2 + worker.MaxWorkers + 1); readAhead > limit {
+		t.Errorf("Expected at most %d targets read ahead, got %d", limit, readAhead)
+	}
+}
+
+// failingChecker сразу возвращает ошибку для URL из fail; остальные проверки
+// успешны, а с wait длятся до отмены контекста
+type failingChecker struct {
+	fail string
+	wait bool
+}
+
+func (c *failingChecker) Check(ctx context.Context, target types.Target) *types.Result {
+	if target.URL == c.fail {
+		return &types.Result{URL: target.URL, Error: fmt.Errorf("connection refused")}
+	}
+	if !c.wait {
+		return &types.Result{URL: target.URL, StatusCode: 200}
+	}
+	<-ctx.Done()
+	return &types.Result{URL: target.URL, Error: ctx.Err()}
+}
+
+func TestWorker_FailFast(t *testing.T) {
+	worker := &Worker{MaxWorkers: 3, FailFast: true}
+	callback, results := makeCollectorCallback()
+
+	urls := make([]string, 20)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("http://slow%d.com", i)
+	}
+	urls[1] = "http://fail.com"
+
+	done := make(chan error, 1)
+	go func() {
+		done <- worker.Run(context.Background(), &failingChecker{fail: "http://fail.com", wait: true}, types.NewTargets(urls), callback)
+	}()
+
+	select {
+	case err := <-done:
+		var stopErr *StopError
+		if !errors.As(err, &stopErr) || !errors.Is(err, ErrStopped) {
+			t.Fatalf("Expected ErrStopped, got %v", err)
+		}
+		// Прервать можно только проверки, уже занявшие воркеры
+		if stopErr.Interrupted > worker.MaxWorkers {
+			t.Errorf("Expected at most %d interrupted checks, got %d", worker.MaxWorkers, stopErr.Interrupted)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Worker didn't stop after first failure")
+	}
+
+	// Прерванные проверки не должны попадать в результаты
+	if len(*results) != 1 || (*results)[0].URL != "http://fail.com" {
+		t.Errorf("Expected only the failed result, got %+v", *results)
+	}
+}
+
+// barrierChecker завершает первые n проверок только после того, как все они
+// начались; проверка URL fail неуспешна. Следующие проверки ждут отмены.
+type barrierChecker struct {
+	fail    string
+	n       int32
+	started atomic.Int32
+	all     sync.WaitGroup
+}
+
+func newBarrierChecker(fail string, n int) *barrierChecker {
+	c := &barrierChecker{fail: fail, n: int32(n)}
+	c.all.Add(n)
+	return c
+}
+
+func (c *barrierChecker) Check(ctx context.Context, target types.Target) *types.Result {
+	if c.started.Add(1) > c.n {
+		<-ctx.Done()
+		return &types.Result{URL: target.URL, Error: ctx.Err()}
+	}
+	c.all.Done()
+	c.all.Wait()
+	if target.URL == c.fail {
+		return &types.Result{URL: target.URL, Error: fmt.Errorf("connection refused")}
+	}
+	return &types.Result{URL: target.URL, StatusCode: 200}
+}
+
+func TestWorker_FailFastReportsFinishedChecks(t *testing.T) {
+	worker := &Worker{MaxWorkers: 3, FailFast: true}
+
+	urls := []string{"http://fail.com", "http://a.com", "http://b.com"}
+	for i := 0; i < 10; i++ {
+		urls = append(urls, fmt.Sprintf("http://slow%d.com", i))
+	}
+
+	// Медленный callback дает завершенным проверкам накопиться в буфере
+	// до того, как будет обработан отказ
+	var reported []string
+	callback := func(_, _ int, result *types.Result) {
+		if len(reported) == 0 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		reported = append(reported, result.URL)
+	}
+
+	err := worker.Run(context.Background(), newBarrierChecker("http://fail.com", 3), types.NewTargets(urls), callback)
+	if !errors.Is(err, ErrStopped) {
+		t.Fatalf("Expected ErrStopped, got %v", err)
+	}
+
+	// Успешные проверки, завершившиеся до остановки, тоже сообщаются
+	for _, url := range urls[:3] {
+		if !slices.Contains(reported, url) {
+			t.Errorf("Expected %s in results, got %v", url, reported)
+		}
+	}
+	if len(reported) != 3 {
+		t.Errorf("Expected only the 3 finished checks, got %v", reported)
+	}
+}
+
+func TestWorker_FailFastAllSuccessful(t *testing.T) {
+	worker := &Worker{MaxWorkers: 2, FailFast: true}
+	callback, results := makeCollectorCallback()
+
+	urls := []string{"http://a.com", "http://b.com", "http://c.com"}
+	if err := worker.Run(context.Background(), &checker.MockChecker{}, types.NewTargets(urls), callback); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(*results) != len(urls) {
+		t.Errorf("Expected %d results, got %d", len(urls), len(*results))
+	}
+}
+
+func TestWorker_FailFastWithHostLimits(t *testing.T) {
+	var targets []types.Target
+	for i := 0; i < 2000; i++ {
+		targets = append(targets, types.Target{URL: fmt.Sprintf("http://host%d.com/page%d", i%100, i)})
+	}
+	targets[500].URL = "http://fail.com"
+
+	// Остановка отменяет планировщик по хостам, и воркеры с завершенными
+	// проверками не должны зависнуть на отправке в него
+	for i := 0; i < 20; i++ {
+		worker := &Worker{MaxWorkers: 16, MaxPerHost: 1, FailFast: true}
+		callback, results := makeCollectorCallback()
+
+		done := make(chan error, 1)
+		go func() {
+			done <- worker.Run(context.Background(), &failingChecker{fail: "http://fail.com"}, targets, callback)
+		}()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, ErrStopped) {
+				t.Errorf("Iteration %d: expected ErrStopped, got %v", i, err)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Iteration %d: worker didn't stop after first failure with host limits", i)
+		}
+
+		failed := slices.ContainsFunc(*results, func(r types.Result) bool { return r.URL == "http://fail.com" })
+		if !failed {
+			t.Errorf("Iteration %d: expected the failure among the results", i)
+		}
+	}
+}